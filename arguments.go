@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+//listPathCommands : Lists the executables of every directory in the container's PATH
+const listPathCommands = `IFS=:; for d in $PATH; do ls -1 "$d" 2>/dev/null; done`
+
+//dockerOutputLines : Runs a docker command in the background and returns its output lines
+func dockerOutputLines(ctx context.Context, args ...string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Split(strings.TrimSpace(string(out)), "\n"), nil
+}
+
+//containerCommandsCompleter : Suggests the commands found in the PATH of a running container
+func containerCommandsCompleter(container string) []prompt.Suggest {
+	cacheKey := fmt.Sprintf("commands:%s", container)
+	return memoryCache.Peek(cacheKey, func(ctx context.Context) []prompt.Suggest {
+		return listContainerCommands(ctx, container)
+	})
+}
+
+func listContainerCommands(ctx context.Context, container string) []prompt.Suggest {
+	lines, err := dockerOutputLines(ctx, "exec", container, "sh", "-c", listPathCommands)
+	if err != nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	suggestions := []prompt.Suggest{}
+	for _, line := range lines {
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		suggestions = append(suggestions, prompt.Suggest{Text: line, Description: "Command in " + container})
+	}
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Text < suggestions[j].Text })
+	return suggestions
+}
+
+//containerPathCompleter : Suggests paths inside a running container for words like container:/etc/
+func containerPathCompleter(word string) []prompt.Suggest {
+	i := strings.Index(word, ":")
+	container, path := word[:i], word[i+1:]
+
+	dir := path[:strings.LastIndex(path, "/")+1]
+	listDir := dir
+	if path == "" {
+		dir, listDir = "/", "/"
+	} else if dir == "" {
+		listDir = "."
+	}
+
+	cacheKey := fmt.Sprintf("paths:%s:%s", container, listDir)
+	suggestions := memoryCache.Peek(cacheKey, func(ctx context.Context) []prompt.Suggest {
+		lines, err := dockerOutputLines(ctx, "exec", container, "ls", "-1ap", listDir)
+		if err != nil {
+			return nil
+		}
+
+		suggestions := []prompt.Suggest{}
+		for _, line := range lines {
+			if line == "" || line == "./" || line == "../" {
+				continue
+			}
+			suggestions = append(suggestions, prompt.Suggest{Text: container + ":" + dir + line})
+		}
+		return suggestions
+	})
+	return prompt.FilterHasPrefix(suggestions, word, false)
+}
+
+//localPathCompleter : Suggests files and directories of the local filesystem
+func localPathCompleter(word string) []prompt.Suggest {
+	dir, _ := filepath.Split(word)
+	listDir := dir
+	if listDir == "" {
+		listDir = "."
+	}
+
+	files, err := ioutil.ReadDir(listDir)
+	if err != nil {
+		return []prompt.Suggest{}
+	}
+
+	suggestions := []prompt.Suggest{}
+	for _, file := range files {
+		text := dir + file.Name()
+		if file.IsDir() {
+			text += string(filepath.Separator)
+		}
+		suggestions = append(suggestions, prompt.Suggest{Text: text})
+	}
+	return prompt.FilterHasPrefix(suggestions, word, false)
+}
+
+//copyPathCompleter : Either side of docker cp is a local path or container:path
+func copyPathCompleter(word string) []prompt.Suggest {
+	if strings.Contains(word, ":") {
+		return containerPathCompleter(word)
+	}
+
+	suggestions := []prompt.Suggest{}
+	for _, s := range containerListCompleter(nil) {
+		suggestions = append(suggestions, prompt.Suggest{Text: s.Text + ":", Description: s.Description})
+	}
+	suggestions = append(suggestions, localPathCompleter(word)...)
+
+	return prompt.FilterHasPrefix(suggestions, word, true)
+}
+
+//repositoryCompleter : Suggests the repository names of local images, e.g. for docker tag
+func repositoryCompleter() []prompt.Suggest {
+	seen := map[string]bool{}
+	suggestions := []prompt.Suggest{}
+	for _, image := range model.imageList() {
+		for _, tag := range image.summary.RepoTags {
+			repository := tag
+			if i := strings.LastIndex(tag, ":"); i > strings.LastIndex(tag, "/") {
+				repository = tag[:i]
+			}
+			if repository == "<none>" || seen[repository] {
+				continue
+			}
+			seen[repository] = true
+			suggestions = append(suggestions, prompt.Suggest{Text: repository, Description: "Repository"})
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"sync"
+
+	"docker.io/go-docker/api/types"
+	commands "github.com/mstrYoda/docker-shell/lib"
+)
+
+var catalogLock sync.RWMutex
+
+func newCatalog() *commands.Commands {
+	catalog := commands.New()
+	return &catalog
+}
+
+//catalog : The command catalog the completer works with
+func catalog() *commands.Commands {
+	catalogLock.RLock()
+	defer catalogLock.RUnlock()
+
+	return shellCommands
+}
+
+//refreshCatalog : Rebuilds the catalog from the installed docker CLI in case the compiled in
+//catalog was generated for another docker version or never generated at all. The introspected
+//catalog is kept in the cache directory, the next session takes it over while the docker
+//version is the same and offline sessions take it over without asking docker
+func refreshCatalog(offline bool) {
+	path := cachePath("catalog.json")
+	persisted, persistedErr := commands.LoadCatalog(path)
+	if offline {
+		if persistedErr == nil {
+			setCatalog(persisted)
+		}
+		return
+	}
+
+	version, err := commands.InstalledVersion(commands.DockerHelpRunner)
+	if err != nil || version == catalog().Version {
+		return
+	}
+	if persistedErr == nil && persisted.Version == version {
+		setCatalog(persisted)
+		return
+	}
+
+	introspected, err := commands.Introspect(commands.DockerHelpRunner, version)
+	if err != nil || len(introspected.DockerSuggestions) <= 1 {
+		return
+	}
+	setCatalog(introspected)
+	if path != "" {
+		introspected.Save(path)
+	}
+}
+
+//setCatalog : Replaces the catalog, keeping the connected daemon
+func setCatalog(replacement commands.Commands) {
+	catalogLock.Lock()
+	defer catalogLock.Unlock()
+
+	replacement.Daemon = shellCommands.Daemon
+	shellCommands = &replacement
+}
+
+//connectCatalog : Records the connected daemon so the catalog stops suggesting what it does not support
+func connectCatalog(ping types.Ping, version types.Version) {
+	daemon := commands.Daemon{
+		APIVersion:   ping.APIVersion,
+		Version:      version.Version,
+		OSType:       ping.OSType,
+		Experimental: ping.Experimental || version.Experimental,
+	}
+	if daemon.APIVersion == "" {
+		daemon.APIVersion = version.APIVersion
+	}
+	if daemon.OSType == "" {
+		daemon.OSType = version.Os
+	}
+
+	catalogLock.Lock()
+	connected := *shellCommands
+	connected.Daemon = daemon
+	shellCommands = &connected
+	catalogLock.Unlock()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+	commands "github.com/mstrYoda/docker-shell/lib"
+)
+
+func TestRefreshCatalogOfflineTakesOverPersistedCatalog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	previous := shellCommands
+	defer func() { shellCommands = previous }()
+	daemon := commands.Daemon{APIVersion: "1.41"}
+	shellCommands = &commands.Commands{Daemon: daemon}
+
+	persisted := commands.Commands{
+		Version: "99.0.0",
+		DockerSuggestions: []prompt.Suggest{
+			{Text: "exit", Description: "Exit command prompt"},
+			{Text: "ps", Description: "List containers"},
+		},
+		Flags:  map[string][]commands.Flag{"ps": {{Name: "--all", Short: "-a"}}},
+		Daemon: commands.Daemon{APIVersion: "1.12"},
+	}
+	if err := persisted.Save(cachePath("catalog.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	refreshCatalog(true)
+	if catalog().Version != "99.0.0" || catalog().Root == nil || len(catalog().Root.Children) != 2 {
+		t.Fatalf("catalog = %+v, want the persisted one with its tree", catalog())
+	}
+	if catalog().Daemon != daemon {
+		t.Errorf("daemon = %+v, want the connected %+v", catalog().Daemon, daemon)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"docker.io/go-docker/api/types"
+	"github.com/c-bata/go-prompt"
+	commands "github.com/mstrYoda/docker-shell/lib"
+)
+
+//containerStates : Container states accepted by a command, an empty list accepts every state
+type containerStates struct {
+	states []string
+	//forced : Extra states accepted once --force is given
+	forced []string
+	//multiple : The command accepts more than one container, otherwise only the first argument is one
+	multiple bool
+}
+
+var (
+	runningStates = []string{"running"}
+	stoppedStates = []string{"created", "exited", "dead"}
+)
+
+//containerCommands : Every command taking containers as arguments with the states it accepts
+var containerCommands = map[string]containerStates{
+	"attach":  {states: runningStates},
+	"commit":  {},
+	"diff":    {},
+	"exec":    {states: runningStates},
+	"export":  {},
+	"inspect": {multiple: true},
+	"kill":    {states: runningStates, multiple: true},
+	"logs":    {},
+	"pause":   {states: runningStates, multiple: true},
+	"port":    {states: runningStates},
+	"rename":  {},
+	"restart": {states: runningStates, multiple: true},
+	"rm":      {states: stoppedStates, forced: []string{"running", "paused", "restarting"}, multiple: true},
+	"start":   {states: []string{"created", "exited"}, multiple: true},
+	"stats":   {states: runningStates, multiple: true},
+	"stop":    {states: runningStates, multiple: true},
+	"top":     {states: runningStates},
+	"unpause": {states: []string{"paused"}, multiple: true},
+	"update":  {multiple: true},
+	"wait":    {multiple: true},
+}
+
+//accepted : The accepted states, including the forced ones once --force is given
+func (c containerStates) accepted(force bool) []string {
+	if force {
+		return append(append([]string{}, c.states...), c.forced...)
+	}
+	return c.states
+}
+
+//containerArgumentCompleter : Suggests containers in the states the command accepts
+func containerArgumentCompleter(ctx commands.Context, word string) ([]prompt.Suggest, bool) {
+	states, ok := containerCommands[ctx.Canonical()]
+	if !ok {
+		return nil, false
+	}
+
+	if !states.multiple && ctx.ArgIndex() != 0 {
+		return nil, false
+	}
+
+	accepted := states.accepted(ctx.HasFlag("-f", "--force"))
+	if states.multiple {
+		return filterContainers(containerListCompleter(accepted, ctx.Args...), word), true
+	}
+	return filterContainers(containerListCompleter(accepted), word), true
+}
+
+//containerListCompleter : Suggests both the short ID and the name of every container in one of
+//the states, containers referred to by one of the excluded words are left out
+func containerListCompleter(states []string, exclude ...string) []prompt.Suggest {
+	suggestions := []prompt.Suggest{}
+	cList := model.containerList(states)
+
+	excluded := map[string]bool{}
+	for _, word := range exclude {
+		excluded[word] = true
+	}
+
+	for _, container := range cList {
+		shortID := container.ID
+		if len(shortID) > 12 {
+			shortID = shortID[:12]
+		}
+		names := []string{}
+		for _, name := range container.Names {
+			names = append(names, strings.TrimPrefix(name, "/"))
+		}
+
+		if excluded[shortID] || excluded[container.ID] || containsAny(excluded, names) {
+			continue
+		}
+
+		description := getContainerDescription(container)
+		for _, name := range names {
+			suggestions = append(suggestions, prompt.Suggest{Text: name, Description: description})
+		}
+		suggestions = append(suggestions, prompt.Suggest{Text: shortID, Description: description})
+	}
+
+	return suggestions
+}
+
+func getContainerDescription(container types.Container) string {
+	parts := []string{container.Status}
+	if ports := formatPorts(container.Ports); ports != "" {
+		parts = append(parts, ports)
+	}
+	if project := container.Labels["com.docker.compose.project"]; project != "" {
+		parts = append(parts, "project: "+project)
+	}
+	parts = append(parts, container.Image)
+
+	return strings.Join(parts, " | ")
+}
+
+func formatPorts(ports []types.Port) string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, port := range ports {
+		text := fmt.Sprintf("%d/%s", port.PrivatePort, port.Type)
+		if port.PublicPort != 0 {
+			text = fmt.Sprintf("%d->%s", port.PublicPort, text)
+		}
+		if !seen[text] {
+			seen[text] = true
+			result = append(result, text)
+		}
+	}
+	return strings.Join(result, ", ")
+}
+
+func containsAny(set map[string]bool, words []string) bool {
+	for _, word := range words {
+		if set[word] {
+			return true
+		}
+	}
+	return false
+}
+
+//filterContainers : Fuzzy matches the word against the name and ID of the suggested containers,
+//so that typing api finds orders-api-1, and looks for it in their status, ports, project and
+//image. Names starting with the word come first, then other name matches, then the containers
+//only found by their description
+func filterContainers(suggestions []prompt.Suggest, word string) []prompt.Suggest {
+	if word == "" {
+		return suggestions
+	}
+
+	prefixed, named, described := []prompt.Suggest{}, []prompt.Suggest{}, []prompt.Suggest{}
+	lower := strings.ToLower(word)
+	for _, s := range suggestions {
+		switch {
+		case strings.HasPrefix(strings.ToLower(s.Text), lower):
+			prefixed = append(prefixed, s)
+		case fuzzyMatch(s.Text, word):
+			named = append(named, s)
+		case strings.Contains(strings.ToLower(s.Description), lower):
+			described = append(described, s)
+		}
+	}
+	return append(append(prefixed, named...), described...)
+}
+
+//fuzzyMatch : Reports whether the characters of sub appear in s in the same order
+func fuzzyMatch(s, sub string) bool {
+	s, sub = strings.ToLower(s), strings.ToLower(sub)
+	for _, c := range sub {
+		i := strings.IndexRune(s, c)
+		if i == -1 {
+			return false
+		}
+		s = s[i+len(string(c)):]
+	}
+	return true
+}
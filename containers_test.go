@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestFilterContainers(t *testing.T) {
+	suggestions := []prompt.Suggest{
+		{Text: "web", Description: "Exited (0) 2 hours ago | nginx:alpine"},
+		{Text: "orders-api-1", Description: "Up 3 minutes | 8080->80/tcp | project: orders | orders-api"},
+		{Text: "api-gateway", Description: "Up 1 hour | traefik"},
+		{Text: "cache", Description: "Up 1 hour | 6379/tcp | project: shop | redis"},
+		{Text: "3f2a9c1b0d4e", Description: "Up 1 hour | 6379/tcp | project: shop | redis"},
+	}
+
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"", []string{"web", "orders-api-1", "api-gateway", "cache", "3f2a9c1b0d4e"}},
+		{"api", []string{"api-gateway", "orders-api-1"}},
+		{"oa1", []string{"orders-api-1"}},
+		{"3f2", []string{"3f2a9c1b0d4e"}},
+		{"shop", []string{"cache", "3f2a9c1b0d4e"}},
+		{"REDIS", []string{"cache", "3f2a9c1b0d4e"}},
+		{"8080", []string{"orders-api-1"}},
+		{"alp", []string{"web"}},
+		{"xyz", []string{}},
+	}
+
+	for _, tt := range tests {
+		got := []string{}
+		for _, s := range filterContainers(suggestions, tt.word) {
+			got = append(got, s.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterContainers(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
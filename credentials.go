@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"docker.io/go-docker/api/types"
+	"github.com/mstrYoda/docker-shell/lib/registry"
+)
+
+//hubLogin : The Docker Hub API token docker login's credentials were exchanged for. The exchange
+//is tried once per session, again when the token stops working or the docker config changed
+type hubLogin struct {
+	lock    sync.Mutex
+	token   string
+	user    string
+	tried   bool
+	changed time.Time
+	//generation : Counts the resets, a login finishing after a reset is thrown away
+	generation int
+}
+
+var hubSession = &hubLogin{}
+
+//dockerCredentials : The credentials docker login stored for the registry host, empty when
+//there are none or the credential helper failed
+func dockerCredentials(ctx context.Context, host string) registry.Credentials {
+	config, err := registry.LoadConfig(dockerConfigPath())
+	if err != nil {
+		return registry.Credentials{}
+	}
+
+	credentials, err := config.Credentials(ctx, host)
+	if err != nil {
+		return registry.Credentials{}
+	}
+	return credentials
+}
+
+//registryAuth : The Docker Hub credentials encoded the way the daemon expects them in
+//X-Registry-Auth, empty to search anonymously
+func registryAuth(ctx context.Context) string {
+	credentials := dockerCredentials(ctx, "docker.io")
+	if credentials.Empty() {
+		return ""
+	}
+
+	auth, err := json.Marshal(types.AuthConfig{
+		Username:      credentials.Username,
+		Password:      credentials.Password,
+		IdentityToken: credentials.IdentityToken,
+		ServerAddress: registry.IndexServer,
+	})
+	if err != nil {
+		return ""
+	}
+	return base64.URLEncoding.EncodeToString(auth)
+}
+
+//Token : The Docker Hub API token and the user it belongs to, empty when not logged in. The user is
+//empty as well for identity token logins. The credentials are only resolved to log in, and the
+//login runs outside the lock: callers asking meanwhile go on anonymously
+func (h *hubLogin) Token(ctx context.Context) (string, string) {
+	changed := dockerConfigChanged()
+
+	h.lock.Lock()
+	if !changed.Equal(h.changed) {
+		h.reset()
+		h.changed = changed
+	}
+	if h.tried {
+		token, user := h.token, h.user
+		h.lock.Unlock()
+		return token, user
+	}
+	h.tried = true
+	generation := h.generation
+	h.lock.Unlock()
+
+	token, user := hubToken(ctx, dockerCredentials(ctx, "docker.io"))
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.generation == generation {
+		h.token, h.user = token, user
+	}
+	return token, user
+}
+
+//hubToken : Logs into the Docker Hub API with the credentials, by password or identity token
+func hubToken(ctx context.Context, credentials registry.Credentials) (string, string) {
+	client := &http.Client{Timeout: 2 * time.Second}
+	switch {
+	case credentials.IdentityToken != "":
+		token, err := registry.HubIdentityToken(ctx, client, registry.HubAuthURL, credentials)
+		if err == nil {
+			return token, credentials.Username
+		}
+	case credentials.Username != "":
+		token, err := registry.HubToken(ctx, client, registry.HubLoginURL, credentials)
+		if err == nil {
+			return token, credentials.Username
+		}
+	}
+	return "", ""
+}
+
+//Expire : Forgets a token the Docker Hub API rejected, the next call logs in again
+func (h *hubLogin) Expire() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.reset()
+}
+
+func (h *hubLogin) reset() {
+	h.token, h.user, h.tried = "", "", false
+	h.generation++
+}
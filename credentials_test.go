@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"docker.io/go-docker/api/types"
+	"github.com/mstrYoda/docker-shell/lib/registry"
+	"github.com/mstrYoda/docker-shell/lib/registry/registrytest"
+)
+
+//fakeDockerConfig : Points DOCKER_CONFIG at a directory with the config and puts
+//docker-credential-fake first on PATH until the returned func runs
+func fakeDockerConfig(t *testing.T, config string) (string, func()) {
+	restoreHelper := registrytest.InstallFakeHelper(t)
+	dir, err := ioutil.TempDir("", "docker-config")
+	if err != nil {
+		restoreHelper()
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		restoreHelper()
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	configDir := os.Getenv("DOCKER_CONFIG")
+	os.Setenv("DOCKER_CONFIG", dir)
+	return dir, func() {
+		os.Setenv("DOCKER_CONFIG", configDir)
+		os.RemoveAll(dir)
+		restoreHelper()
+	}
+}
+
+//touch : Writes the file and moves its modification time by the offset from the one it had
+func touch(t *testing.T, path, content string, offset time.Duration) {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed := info.ModTime().Add(offset)
+	if err := os.Chtimes(path, changed, changed); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistryAuth(t *testing.T) {
+	_, restore := fakeDockerConfig(t, `{"credsStore":"fake"}`)
+	defer restore()
+
+	encoded := registryAuth(context.Background())
+	decoded, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("registryAuth = %q is not url safe base64: %v", encoded, err)
+	}
+	auth := types.AuthConfig{}
+	if err := json.Unmarshal(decoded, &auth); err != nil {
+		t.Fatal(err)
+	}
+	want := types.AuthConfig{Username: "hubuser", Password: "hubpw", ServerAddress: registry.IndexServer}
+	if auth != want {
+		t.Errorf("registryAuth = %+v, want %+v", auth, want)
+	}
+
+	if credentials := dockerCredentials(context.Background(), "unknown.corp"); !credentials.Empty() {
+		t.Errorf("dockerCredentials(unknown.corp) = %+v, want none", credentials)
+	}
+}
+
+func TestRegistryAuthWithoutLogin(t *testing.T) {
+	_, restore := fakeDockerConfig(t, `{}`)
+	defer restore()
+
+	if encoded := registryAuth(context.Background()); encoded != "" {
+		t.Errorf("registryAuth = %q without a login, want nothing", encoded)
+	}
+}
+
+func TestHostSuggestionsReadConfigOnChange(t *testing.T) {
+	dir, restore := fakeDockerConfig(t, `{"auths":{"reg.corp":{}}}`)
+	defer restore()
+
+	r := &registrySet{clients: map[string]*registry.Client{}, insecure: map[string]bool{}}
+	if suggestions := r.hostSuggestions("reg"); len(suggestions) != 1 || suggestions[0].Text != "reg.corp/" {
+		t.Fatalf("hostSuggestions = %v, want reg.corp/", suggestions)
+	}
+
+	path := filepath.Join(dir, "config.json")
+	touch(t, path, `{"auths":{"reg.corp":{},"reg.other":{}}}`, 0)
+	if suggestions := r.hostSuggestions("reg"); len(suggestions) != 1 {
+		t.Errorf("hostSuggestions = %v, want the config read before", suggestions)
+	}
+
+	touch(t, path, `{"auths":{"reg.corp":{},"reg.other":{}}}`, time.Second)
+	if suggestions := r.hostSuggestions("reg"); len(suggestions) != 2 {
+		t.Errorf("hostSuggestions = %v, want the changed config", suggestions)
+	}
+}
+
+func TestRegistryClientsFollowLogins(t *testing.T) {
+	dir, restore := fakeDockerConfig(t, `{}`)
+	defer restore()
+
+	r := &registrySet{clients: map[string]*registry.Client{}, insecure: map[string]bool{"reg.other": false}}
+	if client := r.client(context.Background(), "reg.other"); !client.Credentials.Empty() {
+		t.Fatalf("credentials = %+v before docker login, want none", client.Credentials)
+	}
+
+	touch(t, filepath.Join(dir, "config.json"), `{"auths":{"reg.other":{"auth":"Ym9iOnB3"}}}`, time.Second)
+	client := r.client(context.Background(), "reg.other")
+	if want := (registry.Credentials{Username: "bob", Password: "pw"}); client.Credentials != want {
+		t.Errorf("credentials = %+v after docker login, want %+v", client.Credentials, want)
+	}
+	if again := r.client(context.Background(), "reg.other"); again != client {
+		t.Error("the client was created again although the config did not change")
+	}
+}
+
+func TestHubLoginResetsWhenConfigChanges(t *testing.T) {
+	dir, restore := fakeDockerConfig(t, `{}`)
+	defer restore()
+
+	h := &hubLogin{}
+	if token, user := h.Token(context.Background()); token != "" || user != "" {
+		t.Fatalf("Token = %q, %q without a login, want nothing", token, user)
+	}
+	generation := h.generation
+	h.Token(context.Background())
+	if h.generation != generation || !h.tried {
+		t.Error("an unchanged config logged in again")
+	}
+
+	touch(t, filepath.Join(dir, "config.json"), `{"auths":{}}`, time.Second)
+	h.Token(context.Background())
+	if h.generation == generation {
+		t.Error("a changed config did not log in again")
+	}
+
+	generation = h.generation
+	h.Expire()
+	if h.tried || h.generation == generation {
+		t.Error("Expire did not reset the login")
+	}
+}
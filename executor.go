@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"github.com/mstrYoda/docker-shell/lib/tokenizer"
+)
+
+var lastExitCode int
+
+func newTokenizer() *tokenizer.Tokenizer {
+	t := tokenizer.New()
+	t.Lookup = lookupVariable
+	return t
+}
+
+//lookupVariable : Resolves environment variables, $? expands to the exit code of the last command
+func lookupVariable(name string) (string, bool) {
+	if name == "?" {
+		return strconv.Itoa(lastExitCode), true
+	}
+
+	return os.LookupEnv(name)
+}
+
+func newCommand(args []string) *exec.Cmd {
+	if len(args) > 0 && args[0] == "clear" {
+		return exec.Command("clear")
+	}
+
+	return exec.Command("docker", args...)
+}
+
+//runCommand : Runs the command with its output wired straight to the terminal
+//so that progress bars, colors and follow-mode output are shown as they arrive
+func runCommand(ps *exec.Cmd, ownGroup bool) error {
+	ps.Stdout = os.Stdout
+	ps.Stderr = os.Stderr
+	if ownGroup {
+		startInProcessGroup(ps)
+	}
+
+	if err := ps.Start(); err != nil {
+		return err
+	}
+
+	stop := forwardSignals(ps, ownGroup)
+	defer stop()
+
+	return ps.Wait()
+}
+
+//runInteractiveCommand : Hands the real terminal to the child process. Docker puts
+//the terminal into raw mode and follows window size changes (SIGWINCH) by itself,
+//so the only thing left to do is giving the terminal back to go-prompt afterwards.
+//The child stays in the foreground process group since it reads from the terminal
+func runInteractiveCommand(ps *exec.Cmd) error {
+	state, err := saveTerminalState()
+	if err == nil {
+		defer state.restore()
+	}
+
+	ps.Stdin = os.Stdin
+
+	return runCommand(ps, false)
+}
+
+func execute(args []string) error {
+	ps := newCommand(args)
+	if isInteractive(args) {
+		return runInteractiveCommand(ps)
+	}
+
+	return runCommand(ps, true)
+}
+
+//isInteractive : Reports whether the docker command wants to read from the terminal. Only the flags
+//of docker itself count, the parser stops at the image or container of run, create and exec
+//so flags of the command run inside the container are not taken for them, and flags set to
+//false like --interactive=false do not count
+func isInteractive(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	ctx := catalog().Parse(append(append([]string{}, args...), ""))
+	_, interactive := ctx.Flags["--interactive"]
+	switch ctx.Canonical() {
+	case "attach":
+		return true
+	case "exec", "run", "create":
+		return interactive
+	case "start":
+		_, attach := ctx.Flags["--attach"]
+		return attach && interactive
+	}
+
+	return false
+}
+
+//exitCode : Converts the result of a finished command to a shell style exit code,
+//commands killed by a signal report 128+signal and commands that could not be started 127
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return 127
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
+
+func promptPrefix() string {
+	if lastExitCode != 0 {
+		return "[" + strconv.Itoa(lastExitCode) + "] >>> docker "
+	}
+
+	return ">>> docker "
+}
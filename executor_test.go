@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestIsInteractive(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"ps", "-a"}, false},
+		{[]string{"attach", "web"}, true},
+		{[]string{"run", "-it", "alpine"}, true},
+		{[]string{"run", "--interactive=false", "alpine"}, false},
+		{[]string{"run", "--interactive=true", "alpine"}, true},
+		{[]string{"run", "alpine", "sh", "-i"}, false},
+		{[]string{"--debug", "exec", "-i", "web", "sh"}, true},
+		{[]string{"start", "-a", "-i", "web"}, true},
+		{[]string{"start", "--attach=false", "-i", "web"}, false},
+		{[]string{"start", "-i", "web"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isInteractive(tt.args); got != tt.want {
+			t.Errorf("isInteractive(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
@@ -20,6 +20,6 @@ require (
 	github.com/opencontainers/image-spec v1.0.1 // indirect
 	github.com/patrickmn/go-cache v2.1.0+incompatible
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/pkg/term v0.0.0-20190109203006-aa71e9d9e942 // indirect
+	github.com/pkg/term v0.0.0-20190109203006-aa71e9d9e942
 	golang.org/x/net v0.0.0-20200114155413-6afb5195e5aa // indirect
 )
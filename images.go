@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+	commands "github.com/mstrYoda/docker-shell/lib"
+)
+
+//imageArguments : How a command takes local images as arguments
+type imageArguments struct {
+	//multiple : Every argument is an image, otherwise only the first one is
+	multiple bool
+}
+
+//imageCommands : Every command taking local images as arguments, keyed by command path
+var imageCommands = map[string]imageArguments{
+	"create":        {},
+	"history":       {},
+	"image inspect": {multiple: true},
+	"push":          {},
+	"rmi":           {multiple: true},
+	"run":           {},
+	"save":          {multiple: true},
+	"tag":           {},
+}
+
+//imageArgumentCompleter : Suggests local images where the command expects one
+func imageArgumentCompleter(ctx commands.Context, word string) ([]prompt.Suggest, bool) {
+	arguments, ok := imageCommands[ctx.Command()]
+	if !ok {
+		arguments, ok = imageCommands[ctx.Canonical()]
+	}
+	if !ok || (!arguments.multiple && ctx.ArgIndex() != 0) {
+		return nil, false
+	}
+
+	suggestions := imageCompleter(word)
+	if arguments.multiple {
+		suggestions = excludeTyped(suggestions, ctx.Args)
+	}
+	return suggestions, true
+}
+
+//imageCompleter : Suggests every repo:tag of the local images, the most recent first. Untagged
+//images only have their ID, they are suggested once the word looks like the start of one
+func imageCompleter(word string) []prompt.Suggest {
+	seen := map[string]bool{}
+	suggestions := []prompt.Suggest{}
+	for _, image := range model.imageList() {
+		description := getImageDescription(image)
+		for _, tag := range image.summary.RepoTags {
+			if tag == "<none>:<none>" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			suggestions = append(suggestions, prompt.Suggest{Text: tag, Description: description})
+		}
+
+		if !isTagged(image) && isIDPrefix(word) {
+			shortID := strings.TrimPrefix(image.summary.ID, "sha256:")
+			if len(shortID) > 12 {
+				shortID = shortID[:12]
+			}
+			if !seen[shortID] {
+				seen[shortID] = true
+				suggestions = append(suggestions, prompt.Suggest{Text: shortID, Description: "<none> | " + description})
+			}
+		}
+	}
+	return prompt.FilterFuzzy(suggestions, word, true)
+}
+
+func isTagged(image imageEntry) bool {
+	for _, tag := range image.summary.RepoTags {
+		if tag != "<none>:<none>" {
+			return true
+		}
+	}
+	return false
+}
+
+//isIDPrefix : Reports whether the word could be the start of an image ID
+func isIDPrefix(word string) bool {
+	word = strings.TrimPrefix(word, "sha256:")
+	if word == "" {
+		return false
+	}
+
+	for _, r := range word {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func getImageDescription(image imageEntry) string {
+	parts := []string{humanSize(image.summary.Size), humanAge(time.Unix(image.summary.Created, 0))}
+	if image.architecture != "" {
+		parts = append(parts, image.architecture)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func humanSize(size int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1000 && unit < len(units)-1 {
+		value /= 1000
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d%s", size, units[unit])
+	}
+	return fmt.Sprintf("%.3g%s", value, units[unit])
+}
+
+//humanCount : Counts the way DockerHub shows them, like 19.6k or 1.2B
+func humanCount(count int64) string {
+	units := []string{"", "k", "M", "B"}
+	value := float64(count)
+	unit := 0
+	for value >= 1000 && unit < len(units)-1 {
+		value /= 1000
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d", count)
+	}
+	return fmt.Sprintf("%.3g%s", value, units[unit])
+}
+
+func humanAge(created time.Time) string {
+	age := time.Since(created)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return pluralize(int(age.Minutes()), "minute") + " ago"
+	case age < 24*time.Hour:
+		return pluralize(int(age.Hours()), "hour") + " ago"
+	case age < 30*24*time.Hour:
+		return pluralize(int(age.Hours()/24), "day") + " ago"
+	case age < 365*24*time.Hour:
+		return pluralize(int(age.Hours()/24/30), "month") + " ago"
+	}
+	return pluralize(int(age.Hours()/24/365), "year") + " ago"
+}
@@ -4,13 +4,40 @@ import (
 	"github.com/c-bata/go-prompt"
 )
 
+//go:generate go run ./gencatalog -o commands_generated.go
+
 type Commands struct {
+	//Version : The docker CLI version the catalog was generated from, empty for the built-in one
+	Version              string
 	DockerSuggestions    []prompt.Suggest
 	DockerSubSuggestions map[string][]prompt.Suggest
+	//Flags : Flags of each command path as documented by docker --help
+	Flags map[string][]Flag
+	//Usage : Usage line of each command path, it describes the positional arguments
+	Usage map[string]string
+	//Aliases : Alternative names of each command path under the same parent
+	Aliases map[string][]string
+	//Root : The catalog as a tree of commands, built from the maps above
+	Root *Node `json:"-"`
+	//Daemon : The connected daemon, commands and flags it does not support are not suggested
+	Daemon Daemon `json:"-"`
 }
 
+//generatedCommands : Set by commands_generated.go once go generate was run
+var generatedCommands *Commands
+
+//New : The catalog generated by go generate, or the built-in one when it was never generated
 func New() Commands {
-	return Commands{
+	if generatedCommands != nil {
+		return withTree(*generatedCommands)
+	}
+
+	return Builtin()
+}
+
+//Builtin : The hand-written catalog
+func Builtin() Commands {
+	return withTree(Commands{
 		DockerSuggestions: []prompt.Suggest{
 			{Text: "attach", Description: "Attach local standard input, output, and error streams to a running container"},
 			{Text: "build", Description: "Build an image from a Dockerfile"},
@@ -438,6 +465,11 @@ func New() Commands {
 			"stack": {
 				prompt.Suggest{Text: "--kubeconfig", Description: ""},
 				prompt.Suggest{Text: "--orchestrator", Description: "Orchestrator to use (swarm|kubernetes|all)"},
+				{Text: "deploy", Description: "Deploy a new stack or update an existing stack"},
+				{Text: "ls", Description: "List stacks"},
+				{Text: "ps", Description: "List the tasks in the stack"},
+				{Text: "rm", Description: "Remove one or more stacks"},
+				{Text: "services", Description: "List the services in the stack"},
 			},
 			"start": {
 				prompt.Suggest{Text: "--attach", Description: "Attach STDOUT/STDERR and forward signals"},
@@ -476,6 +508,107 @@ func New() Commands {
 				prompt.Suggest{Text: "--format", Description: "Format the output using the given Go template"},
 				prompt.Suggest{Text: "--kubeconfig", Description: ""},
 			},
+			"config": {
+				{Text: "create", Description: "Create a config from a file or STDIN"},
+				{Text: "inspect", Description: "Display detailed information on one or more configs"},
+				{Text: "ls", Description: "List configs"},
+				{Text: "rm", Description: "Remove one or more configs"},
+			},
+			"context": {
+				{Text: "create", Description: "Create a context"},
+				{Text: "export", Description: "Export a context to a tar or kubeconfig file"},
+				{Text: "import", Description: "Import a context from a tar or zip file"},
+				{Text: "inspect", Description: "Display detailed information on one or more contexts"},
+				{Text: "ls", Description: "List contexts"},
+				{Text: "rm", Description: "Remove one or more contexts"},
+				{Text: "update", Description: "Update a context"},
+				{Text: "use", Description: "Set the current docker context"},
+			},
+			"container": {
+				{Text: "attach", Description: "Attach local standard input, output, and error streams to a running container"},
+				{Text: "commit", Description: "Create a new image from a container’s changes"},
+				{Text: "cp", Description: "Copy files/folders between a container and the local filesystem"},
+				{Text: "create", Description: "Create a new container"},
+				{Text: "diff", Description: "Inspect changes to files or directories on a container’s filesystem"},
+				{Text: "exec", Description: "Run a command in a running container"},
+				{Text: "export", Description: "Export a container’s filesystem as a tar archive"},
+				{Text: "inspect", Description: "Display detailed information on one or more containers"},
+				{Text: "kill", Description: "Kill one or more running containers"},
+				{Text: "logs", Description: "Fetch the logs of a container"},
+				{Text: "ls", Description: "List containers"},
+				{Text: "pause", Description: "Pause all processes within one or more containers"},
+				{Text: "port", Description: "List port mappings or a specific mapping for the container"},
+				{Text: "prune", Description: "Remove all stopped containers"},
+				{Text: "rename", Description: "Rename a container"},
+				{Text: "restart", Description: "Restart one or more containers"},
+				{Text: "rm", Description: "Remove one or more containers"},
+				{Text: "run", Description: "Run a command in a new container"},
+				{Text: "start", Description: "Start one or more stopped containers"},
+				{Text: "stats", Description: "Display a live stream of container(s) resource usage statistics"},
+				{Text: "stop", Description: "Stop one or more running containers"},
+				{Text: "top", Description: "Display the running processes of a container"},
+				{Text: "unpause", Description: "Unpause all processes within one or more containers"},
+				{Text: "update", Description: "Update configuration of one or more containers"},
+				{Text: "wait", Description: "Block until one or more containers stop, then print their exit codes"},
+			},
+			"image": {
+				{Text: "build", Description: "Build an image from a Dockerfile"},
+				{Text: "history", Description: "Show the history of an image"},
+				{Text: "import", Description: "Import the contents from a tarball to create a filesystem image"},
+				{Text: "inspect", Description: "Display detailed information on one or more images"},
+				{Text: "load", Description: "Load an image from a tar archive or STDIN"},
+				{Text: "ls", Description: "List images"},
+				{Text: "prune", Description: "Remove unused images"},
+				{Text: "pull", Description: "Pull an image or a repository from a registry"},
+				{Text: "push", Description: "Push an image or a repository to a registry"},
+				{Text: "rm", Description: "Remove one or more images"},
+				{Text: "save", Description: "Save one or more images to a tar archive (streamed to STDOUT by default)"},
+				{Text: "tag", Description: "Create a tag TARGET_IMAGE that refers to SOURCE_IMAGE"},
+			},
+			"network": {
+				{Text: "connect", Description: "Connect a container to a network"},
+				{Text: "create", Description: "Create a network"},
+				{Text: "disconnect", Description: "Disconnect a container from a network"},
+				{Text: "inspect", Description: "Display detailed information on one or more networks"},
+				{Text: "ls", Description: "List networks"},
+				{Text: "prune", Description: "Remove all unused networks"},
+				{Text: "rm", Description: "Remove one or more networks"},
+			},
+			"node": {
+				{Text: "demote", Description: "Demote one or more nodes from manager in the swarm"},
+				{Text: "inspect", Description: "Display detailed information on one or more nodes"},
+				{Text: "ls", Description: "List nodes in the swarm"},
+				{Text: "promote", Description: "Promote one or more nodes to manager in the swarm"},
+				{Text: "ps", Description: "List tasks running on one or more nodes, defaults to current node"},
+				{Text: "rm", Description: "Remove one or more nodes from the swarm"},
+				{Text: "update", Description: "Update a node"},
+			},
+			"plugin": {
+				{Text: "create", Description: "Create a plugin from a rootfs and configuration"},
+				{Text: "disable", Description: "Disable a plugin"},
+				{Text: "enable", Description: "Enable a plugin"},
+				{Text: "inspect", Description: "Display detailed information on one or more plugins"},
+				{Text: "install", Description: "Install a plugin"},
+				{Text: "ls", Description: "List plugins"},
+				{Text: "push", Description: "Push a plugin to a registry"},
+				{Text: "rm", Description: "Remove one or more plugins"},
+				{Text: "set", Description: "Change settings for a plugin"},
+				{Text: "upgrade", Description: "Upgrade an existing plugin"},
+			},
+			"secret": {
+				{Text: "create", Description: "Create a secret from a file or STDIN as content"},
+				{Text: "inspect", Description: "Display detailed information on one or more secrets"},
+				{Text: "ls", Description: "List secrets"},
+				{Text: "rm", Description: "Remove one or more secrets"},
+			},
+			"volume": {
+				{Text: "create", Description: "Create a volume"},
+				{Text: "inspect", Description: "Display detailed information on one or more volumes"},
+				{Text: "ls", Description: "List volumes"},
+				{Text: "prune", Description: "Remove all unused local volumes"},
+				{Text: "rm", Description: "Remove one or more volumes"},
+			},
+
 			"service": {
 				{Text: "create", Description: "Create a new service"},
 				{Text: "inspect", Description: "Display detailed information on one or more services"},
@@ -677,11 +810,24 @@ func New() Commands {
 				prompt.Suggest{Text: "--workdir", Description: "Working directory inside the container"},
 			},
 		},
-	}
+		Aliases: map[string][]string{
+			"container ls": {"list", "ps"},
+			"container rm": {"remove"},
+			"image ls":     {"list"},
+			"image rm":     {"remove"},
+			"network ls":   {"list"},
+			"network rm":   {"remove"},
+			"volume ls":    {"list"},
+			"volume rm":    {"remove"},
+		},
+	})
 }
 
 func (c *Commands) GetDockerSuggestions() []prompt.Suggest {
-	return c.DockerSuggestions
+	if c.Root == nil {
+		return c.DockerSuggestions
+	}
+	return c.gateCommands(c.Root, c.DockerSuggestions)
 }
 
 func (c *Commands) GetDockerSubSuggestions() map[string][]prompt.Suggest {
@@ -0,0 +1,434 @@
+package commands
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+//booleanFlags : Flags that never take a value, every other long flag does
+var booleanFlags = map[string]bool{
+	"--all":                   true,
+	"--all-tags":              true,
+	"--archive":               true,
+	"--automated":             true,
+	"--compress":              true,
+	"--detach":                true,
+	"--details":               true,
+	"--digests":               true,
+	"--disable-content-trust": true,
+	"--follow":                true,
+	"--follow-link":           true,
+	"--force":                 true,
+	"--force-rm":              true,
+	"--help":                  true,
+	"--human":                 true,
+	"--init":                  true,
+	"--interactive":           true,
+	"--latest":                true,
+	"--no-cache":              true,
+	"--no-healthcheck":        true,
+	"--no-prune":              true,
+	"--no-resolve":            true,
+	"--no-resolve-image":      true,
+	"--no-stdin":              true,
+	"--no-stream":             true,
+	"--no-task-ids":           true,
+	"--no-trunc":              true,
+	"--oom-kill-disable":      true,
+	"--password-stdin":        true,
+	"--pause":                 true,
+	"--pretty":                true,
+	"--privileged":            true,
+	"--publish-all":           true,
+	"--pull":                  true,
+	"--quiet":                 true,
+	"--raw":                   true,
+	"--read-only":             true,
+	"--rm":                    true,
+	"--rollback":              true,
+	"--sig-proxy":             true,
+	"--size":                  true,
+	"--squash":                true,
+	"--stream":                true,
+	"--timestamps":            true,
+	"--tty":                   true,
+	"--volumes":               true,
+	"--with-registry-auth":    true,
+}
+
+//commandBooleanFlags : Flags that are boolean only for some commands
+var commandBooleanFlags = map[string]map[string]bool{
+	"start": {"--attach": true},
+}
+
+//shortFlags : Short names of the flags of the built-in catalog, per command
+var shortFlags = map[string]map[string]string{
+	"build":   {"-f": "--file", "-m": "--memory", "-q": "--quiet", "-t": "--tag"},
+	"commit":  {"-a": "--author", "-c": "--change", "-m": "--message", "-p": "--pause"},
+	"cp":      {"-a": "--archive", "-L": "--follow-link"},
+	"create":  runShortFlags,
+	"exec":    {"-d": "--detach", "-e": "--env", "-i": "--interactive", "-t": "--tty", "-u": "--user", "-w": "--workdir"},
+	"history": {"-H": "--human", "-q": "--quiet"},
+	"images":  {"-a": "--all", "-f": "--filter", "-q": "--quiet"},
+	"import":  {"-c": "--change", "-m": "--message"},
+	"info":    {"-f": "--format"},
+	"inspect": {"-f": "--format", "-s": "--size"},
+	"kill":    {"-s": "--signal"},
+	"load":    {"-i": "--input", "-q": "--quiet"},
+	"login":   {"-p": "--password", "-u": "--username"},
+	"logs":    {"-f": "--follow", "-n": "--tail", "-t": "--timestamps"},
+	"ps":      {"-a": "--all", "-f": "--filter", "-l": "--latest", "-n": "--last", "-q": "--quiet", "-s": "--size"},
+	"pull":    {"-a": "--all-tags", "-q": "--quiet"},
+	"restart": {"-t": "--time"},
+	"rm":      {"-f": "--force", "-l": "--link", "-v": "--volumes"},
+	"rmi":     {"-f": "--force"},
+	"run":     runShortFlags,
+	"save":    {"-o": "--output"},
+	"search":  {"-f": "--filter"},
+	"start":   {"-a": "--attach", "-i": "--interactive"},
+	"stats":   {"-a": "--all"},
+	"stop":    {"-t": "--time"},
+	"update":  {"-c": "--cpu-shares", "-m": "--memory"},
+	"version": {"-f": "--format"},
+
+	"service create": {"-d": "--detach", "-e": "--env", "-l": "--label", "-p": "--publish", "-q": "--quiet", "-t": "--tty", "-u": "--user", "-w": "--workdir"},
+	"service logs":   {"-f": "--follow", "-n": "--tail", "-t": "--timestamps"},
+	"service ls":     {"-f": "--filter", "-q": "--quiet"},
+	"service ps":     {"-f": "--filter", "-q": "--quiet"},
+}
+
+var runShortFlags = map[string]string{
+	"-a": "--attach", "-c": "--cpu-shares", "-d": "--detach", "-e": "--env", "-h": "--hostname",
+	"-i": "--interactive", "-l": "--label", "-m": "--memory", "-p": "--publish", "-P": "--publish-all",
+	"-t": "--tty", "-u": "--user", "-v": "--volume", "-w": "--workdir",
+}
+
+//rootFlags : Options of docker itself given before the command, with whether they take a value
+var rootFlags = map[string]bool{
+	"--config":    true,
+	"--context":   true,
+	"--debug":     false,
+	"--help":      false,
+	"--host":      true,
+	"--log-level": true,
+	"--tls":       false,
+	"--tlscacert": true,
+	"--tlscert":   true,
+	"--tlskey":    true,
+	"--tlsverify": false,
+	"--version":   false,
+}
+
+//rootShortFlags : Short names of the options of docker itself
+var rootShortFlags = map[string]string{
+	"-c": "--context",
+	"-D": "--debug",
+	"-h": "--help",
+	"-H": "--host",
+	"-l": "--log-level",
+	"-v": "--version",
+}
+
+//skipRootFlags : The index of the first word after the options of docker itself, with the option
+//still waiting for its value when the words end before it
+func skipRootFlags(words []string) (int, string) {
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") || word == "-" {
+			return i, ""
+		}
+		if word == "--" {
+			return i + 1, ""
+		}
+
+		if flag := rootFlagWithoutValue(word); flag != "" {
+			if i+1 == len(words) {
+				return len(words), flag
+			}
+			i++
+		}
+	}
+	return len(words), ""
+}
+
+//rootFlagWithoutValue : The option of docker itself in the word that takes its value from the
+//next word, empty when the word carries the value or needs none
+func rootFlagWithoutValue(word string) string {
+	if !isShortFlag(word) {
+		name, _, hasValue := splitFlag(word)
+		if rootFlags[name] && !hasValue {
+			return name
+		}
+		return ""
+	}
+
+	bundle := word[1:]
+	for i := 0; i < len(bundle); i++ {
+		long := rootShortFlags["-"+bundle[i:i+1]]
+		if rootFlags[long] {
+			if i+1 < len(bundle) {
+				return ""
+			}
+			return long
+		}
+	}
+	return ""
+}
+
+//nonInterspersedCommands : Commands whose flags end at the first positional argument,
+//everything after it belongs to the command run inside the container
+var nonInterspersedCommands = map[string]bool{
+	"create": true,
+	"exec":   true,
+	"run":    true,
+}
+
+//TakesValue : Reports whether the flag of the given command is followed by a value
+func (c *Commands) TakesValue(command, flag string) bool {
+	if f, ok := c.lookupFlag(command, flag); ok {
+		return f.Type != ""
+	}
+	return takesValueStatic(CanonicalCommand(command), flag)
+}
+
+//takesValueStatic : TakesValue for flags the catalog does not describe
+func takesValueStatic(command, flag string) bool {
+	if !strings.HasPrefix(flag, "--") {
+		long, ok := shortFlags[command][flag]
+		if !ok {
+			return false
+		}
+		flag = long
+	}
+
+	if commandBooleanFlags[command][flag] {
+		return false
+	}
+	return !booleanFlags[flag]
+}
+
+//LongFlag : The long name of a flag of the given command, e.g. --publish for -p
+func (c *Commands) LongFlag(command, flag string) string {
+	if f, ok := c.lookupFlag(command, flag); ok {
+		return f.Name
+	}
+	if long, ok := shortFlags[CanonicalCommand(command)][flag]; ok {
+		return long
+	}
+	return flag
+}
+
+//isShortFlag : Reports whether the word is one or more bundled short flags like -itd
+func isShortFlag(word string) bool {
+	return len(word) > 1 && word[0] == '-' && word[1] != '-'
+}
+
+//flagWord : One flag of a command line word, bundled short flags yield several
+type flagWord struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+//splitFlags : Splits a flag word into its flags, --name=value and -p8080:80 carry their value,
+//-itd is the same as -i -t -d
+func (c *Commands) splitFlags(command, word string) []flagWord {
+	if !isShortFlag(word) {
+		name, value, hasValue := splitFlag(word)
+		return []flagWord{{name: name, value: value, hasValue: hasValue}}
+	}
+
+	flags := []flagWord{}
+	bundle := word[1:]
+	for i := 0; i < len(bundle); i++ {
+		name := "-" + bundle[i:i+1]
+		if rest := strings.TrimPrefix(bundle[i+1:], "="); i+1 < len(bundle) && c.TakesValue(command, name) {
+			return append(flags, flagWord{name: name, value: rest, hasValue: true})
+		}
+		flags = append(flags, flagWord{name: name})
+	}
+	return flags
+}
+
+//repeatableFlags : Flags of the built-in catalog that may be given more than once
+var repeatableFlags = map[string]bool{
+	"--add-host":     true,
+	"--attach":       true,
+	"--build-arg":    true,
+	"--cap-add":      true,
+	"--cap-drop":     true,
+	"--change":       true,
+	"--config":       true,
+	"--constraint":   true,
+	"--device":       true,
+	"--dns":          true,
+	"--env":          true,
+	"--env-file":     true,
+	"--expose":       true,
+	"--filter":       true,
+	"--label":        true,
+	"--label-file":   true,
+	"--link":         true,
+	"--mount":        true,
+	"--publish":      true,
+	"--secret":       true,
+	"--security-opt": true,
+	"--sysctl":       true,
+	"--tag":          true,
+	"--tmpfs":        true,
+	"--ulimit":       true,
+	"--volume":       true,
+	"--volumes-from": true,
+}
+
+//repeatableTypes : Value types printed by docker --help for flags that may be given more than once
+var repeatableTypes = map[string]bool{
+	"filter":      true,
+	"list":        true,
+	"map":         true,
+	"mount":       true,
+	"stringArray": true,
+	"stringSlice": true,
+	"ulimit":      true,
+}
+
+//flagConflict : Two flags docker rejects or ignores when given together
+type flagConflict struct {
+	flag  string
+	other string
+	//values : Values of other the conflict is limited to, every value when empty
+	values []string
+	//except : Values of other that do not conflict
+	except []string
+	reason string
+}
+
+var flagConflicts = map[string][]flagConflict{
+	"create": runConflicts,
+	"run":    runConflicts,
+}
+
+var runConflicts = []flagConflict{
+	{flag: "--rm", other: "--restart", except: []string{"no"}, reason: "--rm cannot be combined with a restart policy"},
+	{flag: "--detach", other: "--attach", reason: "-d cannot be combined with --attach"},
+	{flag: "--publish", other: "--network", values: []string{"host"}, reason: "published ports are discarded in host network mode"},
+	{flag: "--publish-all", other: "--network", values: []string{"host"}, reason: "published ports are discarded in host network mode"},
+	{flag: "--cpus", other: "--cpu-period", reason: "--cpus cannot be combined with --cpu-period"},
+	{flag: "--cpus", other: "--cpu-quota", reason: "--cpus cannot be combined with --cpu-quota"},
+}
+
+//IsRepeatable : Reports whether the flag of the given command may be given more than once
+func (c *Commands) IsRepeatable(command, flag string) bool {
+	if f, ok := c.lookupFlag(command, flag); ok && repeatableTypes[f.Type] {
+		return true
+	}
+	return repeatableFlags[c.LongFlag(command, flag)]
+}
+
+//FlagConflict : Why the flag conflicts with the flags typed so far, empty when it does not.
+//Conflicts depending on the value of the other flag are only reported once that value was typed
+func (c *Commands) FlagConflict(ctx Context, flag string) string {
+	flag = c.LongFlag(ctx.Command(), flag)
+	for _, conflict := range flagConflicts[ctx.Canonical()] {
+		switch {
+		case conflict.flag == flag && conflict.matches(ctx.Flags[conflict.other]):
+			return conflict.reason
+		case conflict.other == flag && ctx.HasFlag(conflict.flag) && conflict.anyValue():
+			return conflict.reason
+		}
+	}
+	return ""
+}
+
+//ValueConflict : Why giving the value to the flag conflicts with the flags typed so far, empty when
+//it does not. The flags typed so far are checked with FlagConflict as if the value was typed
+func (c *Commands) ValueConflict(ctx Context, flag, value string) string {
+	flag = c.LongFlag(ctx.Command(), flag)
+	with, without := ctx, ctx
+	with.Flags, without.Flags = map[string][]string{}, map[string][]string{}
+	typed := []string{}
+	for name, values := range ctx.Flags {
+		with.Flags[name], without.Flags[name] = values, values
+		if name != flag {
+			typed = append(typed, name)
+		}
+	}
+	with.Flags[flag] = []string{value}
+	delete(without.Flags, flag)
+	sort.Strings(typed)
+
+	for _, name := range typed {
+		if reason := c.FlagConflict(with, name); reason != "" && c.FlagConflict(without, name) == "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+//matches : Reports whether one of the values given to the other flag conflicts
+func (f flagConflict) matches(values []string) bool {
+	for _, value := range values {
+		switch {
+		case len(f.values) > 0 && contains(f.values, value):
+			return true
+		case len(f.values) == 0 && !contains(f.except, value):
+			return true
+		}
+	}
+	return false
+}
+
+//anyValue : Reports whether every value of the other flag conflicts
+func (f flagConflict) anyValue() bool {
+	return len(f.values) == 0 && len(f.except) == 0
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+//RemainingFlags : Drops the flags already typed before the cursor unless they may be repeated
+//and warns in the description about flags conflicting with the typed ones
+func (c *Commands) RemainingFlags(ctx Context, suggestions []prompt.Suggest) []prompt.Suggest {
+	command := ctx.Command()
+	result := []prompt.Suggest{}
+	for _, s := range suggestions {
+		flag := s.Text
+		if isShortFlag(flag) {
+			if flag == ctx.Word {
+				result = append(result, s)
+				continue
+			}
+			flag = "-" + flag[len(flag)-1:]
+		}
+
+		if ctx.HasFlag(c.LongFlag(command, flag)) && !c.IsRepeatable(command, flag) {
+			continue
+		}
+		if reason := c.FlagConflict(ctx, flag); reason != "" {
+			s.Description = "(conflict: " + reason + ") " + s.Description
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+//MarkConflictingValues : Warns in the description about values of the flag under the cursor
+//that conflict with the flags typed so far, the same way RemainingFlags warns about flags
+func (c *Commands) MarkConflictingValues(ctx Context, suggestions []prompt.Suggest) []prompt.Suggest {
+	result := []prompt.Suggest{}
+	for _, s := range suggestions {
+		if reason := c.ValueConflict(ctx, ctx.Flag, s.Text); reason != "" {
+			s.Description = "(conflict: " + reason + ") " + s.Description
+		}
+		result = append(result, s)
+	}
+	return result
+}
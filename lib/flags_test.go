@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestFlagConflict(t *testing.T) {
+	catalog := Builtin()
+
+	tests := []struct {
+		line, flag, want string
+	}{
+		{"run --restart always", "--rm", "--rm cannot be combined with a restart policy"},
+		{"run --restart no", "--rm", ""},
+		{"run --restart", "--rm", ""},
+		{"run --rm", "--restart", ""},
+		{"run -d", "--attach", "-d cannot be combined with --attach"},
+		{"run --attach stdout", "-d", "-d cannot be combined with --attach"},
+		{"run --network host", "-p", "published ports are discarded in host network mode"},
+		{"run --network bridge", "--publish", ""},
+		{"run --rm=false --restart always", "--rm", "--rm cannot be combined with a restart policy"},
+		{"exec -d", "--attach", ""},
+	}
+
+	for _, tt := range tests {
+		ctx := catalog.Parse(append(strings.Fields(tt.line), ""))
+		if got := catalog.FlagConflict(ctx, tt.flag); got != tt.want {
+			t.Errorf("FlagConflict(%q, %s) = %q, want %q", tt.line, tt.flag, got, tt.want)
+		}
+	}
+}
+
+func TestValueConflict(t *testing.T) {
+	catalog := Builtin()
+
+	tests := []struct {
+		line, value, want string
+	}{
+		{"run --rm --restart", "always", "--rm cannot be combined with a restart policy"},
+		{"run --rm --restart", "on-failure", "--rm cannot be combined with a restart policy"},
+		{"run --rm --restart", "no", ""},
+		{"run --restart", "always", ""},
+		{"run -p 80:80 --network", "host", "published ports are discarded in host network mode"},
+		{"run -P --network", "bridge", ""},
+		{"run -d --attach", "stdout", "-d cannot be combined with --attach"},
+		{"run --cpus 2 --cpu-quota", "50000", "--cpus cannot be combined with --cpu-quota"},
+	}
+
+	for _, tt := range tests {
+		ctx := catalog.Parse(append(strings.Fields(tt.line), ""))
+		if ctx.Expect != ExpectFlagValue {
+			t.Fatalf("%q does not expect a flag value", tt.line)
+		}
+		if got := catalog.ValueConflict(ctx, ctx.Flag, tt.value); got != tt.want {
+			t.Errorf("ValueConflict(%q, %s) = %q, want %q", tt.line, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMarkConflictingValues(t *testing.T) {
+	catalog := Builtin()
+	ctx := catalog.Parse([]string{"run", "--rm", "--restart", ""})
+
+	suggestions := catalog.MarkConflictingValues(ctx, []prompt.Suggest{
+		{Text: "no", Description: "Do not restart"},
+		{Text: "always", Description: "Always restart"},
+	})
+	if suggestions[0].Description != "Do not restart" {
+		t.Errorf("no = %q, want it unmarked", suggestions[0].Description)
+	}
+	if want := "(conflict: --rm cannot be combined with a restart policy) Always restart"; suggestions[1].Description != want {
+		t.Errorf("always = %q, want %q", suggestions[1].Description, want)
+	}
+}
@@ -0,0 +1,103 @@
+//gencatalog : Generates the command catalog of the lib package from the installed docker CLI
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"sort"
+
+	"github.com/c-bata/go-prompt"
+	commands "github.com/mstrYoda/docker-shell/lib"
+)
+
+func main() {
+	output := flag.String("o", "commands_generated.go", "file to write the catalog to")
+	flag.Parse()
+
+	version, err := commands.InstalledVersion(commands.DockerHelpRunner)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	catalog, err := commands.Introspect(commands.DockerHelpRunner, version)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	source := &bytes.Buffer{}
+	fmt.Fprintf(source, "// Code generated by gencatalog from docker %s. DO NOT EDIT.\n\n", version)
+	fmt.Fprintln(source, "package commands")
+	fmt.Fprintln(source)
+	fmt.Fprintln(source, `import "github.com/c-bata/go-prompt"`)
+	fmt.Fprintln(source)
+	fmt.Fprintln(source, "func init() {")
+	fmt.Fprintln(source, "generatedCommands = &Commands{")
+	fmt.Fprintf(source, "Version: %q,\n", catalog.Version)
+	fmt.Fprintln(source, "DockerSuggestions: []prompt.Suggest{")
+	writeSuggestions(source, catalog.DockerSuggestions)
+	fmt.Fprintln(source, "},")
+	fmt.Fprintln(source, "DockerSubSuggestions: map[string][]prompt.Suggest{")
+	for _, key := range sortedKeys(catalog.DockerSubSuggestions) {
+		fmt.Fprintf(source, "%q: {\n", key)
+		writeSuggestions(source, catalog.DockerSubSuggestions[key])
+		fmt.Fprintln(source, "},")
+	}
+	fmt.Fprintln(source, "},")
+	fmt.Fprintln(source, "Flags: map[string][]Flag{")
+	for _, key := range sortedKeys(catalog.DockerSubSuggestions) {
+		if len(catalog.Flags[key]) == 0 {
+			continue
+		}
+		fmt.Fprintf(source, "%q: {\n", key)
+		for _, f := range catalog.Flags[key] {
+			fmt.Fprintf(source, "{Name: %q, Short: %q, Type: %q, Default: %q, Description: %q},\n", f.Name, f.Short, f.Type, f.Default, f.Description)
+		}
+		fmt.Fprintln(source, "},")
+	}
+	fmt.Fprintln(source, "},")
+	fmt.Fprintln(source, "Usage: map[string]string{")
+	for _, key := range sortedKeys(catalog.DockerSubSuggestions) {
+		if usage := catalog.Usage[key]; usage != "" {
+			fmt.Fprintf(source, "%q: %q,\n", key, usage)
+		}
+	}
+	fmt.Fprintln(source, "},")
+	fmt.Fprintln(source, "Aliases: map[string][]string{")
+	for _, key := range sortedKeys(catalog.DockerSubSuggestions) {
+		if aliases := catalog.Aliases[key]; len(aliases) > 0 {
+			fmt.Fprintf(source, "%q: %#v,\n", key, aliases)
+		}
+	}
+	fmt.Fprintln(source, "},")
+	fmt.Fprintln(source, "}")
+	fmt.Fprintln(source, "}")
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*output, formatted, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeSuggestions(w io.Writer, suggestions []prompt.Suggest) {
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "{Text: %q, Description: %q},\n", s.Text, s.Description)
+	}
+}
+
+func sortedKeys(m map[string][]prompt.Suggest) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/c-bata/go-prompt"
+)
+
+//Flag : A flag as documented by docker --help, Type is empty for boolean flags
+type Flag struct {
+	Name        string
+	Short       string
+	Type        string
+	Default     string
+	Description string
+	Requires    Requirement
+}
+
+//HelpRunner : Runs the docker CLI with the given arguments and returns its output
+type HelpRunner func(args ...string) (string, error)
+
+//introspectWorkers : Number of docker --help processes run at the same time
+const introspectWorkers = 8
+
+var (
+	sectionExpression = regexp.MustCompile(`^([A-Z][A-Za-z ]*):\s*$`)
+	commandExpression = regexp.MustCompile(`^\s+([a-z][a-z0-9-]*)\*?\s{2,}(.*)$`)
+	flagExpression    = regexp.MustCompile(`^\s+(?:-([A-Za-z0-9]),\s+)?--([a-z0-9][a-z0-9-]*)(?: ([A-Za-z0-9-]+))?\s{2,}(.*)$`)
+	//defaultExpression : A default value closing the description, quoted, a list or a single word.
+	//Prose like "(default shows just running)" is not a default
+	defaultExpression = regexp.MustCompile(`\s*\(default ("[^"]*"|\[[^\]]*\]|[^\s()]+)\)$`)
+	versionExpression = regexp.MustCompile(`version ([^\s,]+)`)
+)
+
+//DockerHelpRunner : Runs the docker binary found in PATH
+func DockerHelpRunner(args ...string) (string, error) {
+	output, err := exec.Command("docker", args...).Output()
+	return string(output), err
+}
+
+//InstalledVersion : The version of the docker CLI, read from docker --version
+func InstalledVersion(run HelpRunner) (string, error) {
+	output, err := run("--version")
+	if err != nil {
+		return "", err
+	}
+
+	match := versionExpression.FindStringSubmatch(output)
+	if match == nil {
+		return "", errors.New("unexpected docker --version output: " + strings.TrimSpace(output))
+	}
+	return match[1], nil
+}
+
+//helpPage : The subcommands and flags listed by one docker --help page
+type helpPage struct {
+	usage    string
+	aliases  []string
+	commands []prompt.Suggest
+	flags    []Flag
+}
+
+//parseHelp : Reads the "Commands" and "Options" sections of a docker --help output
+func parseHelp(text string) helpPage {
+	page := helpPage{}
+	section := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := usageExpression.FindStringSubmatch(line); match != nil {
+			page.usage = strings.TrimSpace(match[1])
+			continue
+		}
+		if match := sectionExpression.FindStringSubmatch(line); match != nil {
+			section = match[1]
+			continue
+		}
+
+		switch {
+		case section == "Aliases":
+			for _, alias := range strings.Split(line, ",") {
+				if alias = strings.TrimSpace(alias); alias != "" {
+					page.aliases = append(page.aliases, alias)
+				}
+			}
+		case strings.HasSuffix(section, "Commands") && !strings.HasPrefix(section, "Invalid"):
+			if match := commandExpression.FindStringSubmatch(line); match != nil {
+				page.commands = append(page.commands, prompt.Suggest{Text: match[1], Description: strings.TrimSpace(match[2])})
+			}
+		case section == "Options" || section == "Flags":
+			if match := flagExpression.FindStringSubmatch(line); match != nil {
+				flag := Flag{Name: "--" + match[2], Type: match[3], Description: strings.TrimSpace(match[4])}
+				if match[1] != "" {
+					flag.Short = "-" + match[1]
+				}
+				page.flags = append(page.flags, flag)
+			} else if n, text := len(page.flags), strings.TrimSpace(line); n > 0 && text != "" && !strings.HasPrefix(text, "-") && strings.HasPrefix(line, "      ") {
+				page.flags[n-1].Description += " " + strings.TrimSpace(line)
+			}
+		}
+	}
+
+	for i := range page.flags {
+		if match := defaultExpression.FindStringSubmatch(page.flags[i].Description); match != nil {
+			page.flags[i].Default = strings.Trim(match[1], `"`)
+			page.flags[i].Description = defaultExpression.ReplaceAllString(page.flags[i].Description, "")
+		}
+	}
+	return page
+}
+
+//Introspect : Builds the catalog of the installed docker CLI by walking docker --help
+//recursively through every command and subcommand
+func Introspect(run HelpRunner, version string) (Commands, error) {
+	root, err := run("--help")
+	if err != nil {
+		return Commands{}, err
+	}
+
+	catalog := Commands{
+		Version:              version,
+		DockerSubSuggestions: map[string][]prompt.Suggest{},
+		Flags:                map[string][]Flag{},
+		Usage:                map[string]string{},
+		Aliases:              map[string][]string{},
+	}
+	page := parseHelp(root)
+	catalog.DockerSuggestions = append(page.commands, prompt.Suggest{Text: "exit", Description: "Exit command prompt"})
+	sort.Slice(catalog.DockerSuggestions, func(i, j int) bool {
+		return catalog.DockerSuggestions[i].Text < catalog.DockerSuggestions[j].Text
+	})
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, introspectWorkers)
+
+	var walk func(path []string)
+	walk = func(path []string) {
+		defer wg.Done()
+
+		workers <- struct{}{}
+		output, err := run(append(append([]string{}, path...), "--help")...)
+		<-workers
+		if err != nil {
+			return
+		}
+
+		page := parseHelp(output)
+		suggestions := append([]prompt.Suggest{}, page.commands...)
+		for _, flag := range page.flags {
+			suggestions = append(suggestions, prompt.Suggest{Text: flag.Name, Description: flag.Description})
+		}
+
+		key := strings.Join(path, " ")
+		lock.Lock()
+		catalog.DockerSubSuggestions[key] = suggestions
+		catalog.Flags[key] = page.flags
+		catalog.Usage[key] = page.usage
+		if aliases := siblingAliases(path, page.aliases); len(aliases) > 0 {
+			catalog.Aliases[key] = aliases
+		}
+		lock.Unlock()
+
+		for _, command := range page.commands {
+			wg.Add(1)
+			go walk(append(append([]string{}, path...), command.Text))
+		}
+	}
+
+	for _, command := range page.commands {
+		wg.Add(1)
+		go walk([]string{command.Text})
+	}
+	wg.Wait()
+
+	return withTree(catalog), nil
+}
+
+//Save : Writes the catalog to path so the next session does not introspect the same docker
+//version again, the tree and the daemon are left out
+func (c Commands) Save(path string) error {
+	content, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+//LoadCatalog : Reads a catalog written by Save and builds its tree again
+func LoadCatalog(path string) (Commands, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Commands{}, err
+	}
+
+	catalog := Commands{}
+	if err := json.Unmarshal(content, &catalog); err != nil {
+		return Commands{}, err
+	}
+	if catalog.Version == "" || len(catalog.DockerSuggestions) <= 1 {
+		return Commands{}, errors.New("no introspected catalog in " + path)
+	}
+	return withTree(catalog), nil
+}
+
+//siblingAliases : The aliases of a command that live under the same parent, docker --help lists
+//them either as bare names or as full command lines like "docker container list, docker ps"
+func siblingAliases(path []string, aliases []string) []string {
+	name := path[len(path)-1]
+	parent := strings.Join(path[:len(path)-1], " ")
+
+	result := []string{}
+	for _, alias := range aliases {
+		words := strings.Fields(alias)
+		if len(words) > 1 && words[0] == "docker" {
+			words = words[1:]
+			if len(words) != len(path) || strings.Join(words[:len(words)-1], " ") != parent {
+				continue
+			}
+		} else if len(words) != 1 {
+			continue
+		}
+
+		if alias := words[len(words)-1]; alias != name {
+			result = append(result, alias)
+		}
+	}
+	return result
+}
+
+//lookupFlag : Finds a flag of the command by its long or short name
+func (c *Commands) lookupFlag(command, name string) (Flag, bool) {
+	if node := c.Lookup(command); node != nil {
+		return node.Flag(name)
+	}
+	return Flag{}, false
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+//psHelp : docker ps --help of docker 24
+const psHelp = `
+Usage:  docker ps [OPTIONS]
+
+List containers
+
+Aliases:
+  docker container ls, docker container list, docker container ps, docker ps
+
+Options:
+  -a, --all             Show all containers (default shows just running)
+  -f, --filter filter   Filter output based on conditions provided
+      --format string   Format output using a custom template:
+                        'table':            Print output in table format with column headers (default)
+                        'table TEMPLATE':   Print output in table format using the given Go template
+                        'json':             Print in JSON format
+  -n, --last int        Show n last created containers (includes all states) (default -1)
+  -l, --latest          Show the latest created container (includes all states)
+      --no-trunc        Don't truncate output
+  -q, --quiet           Only display container IDs
+  -s, --size            Display total file sizes
+`
+
+//runHelp : Part of docker run --help of docker 24
+const runHelp = `
+Usage:  docker run [OPTIONS] IMAGE [COMMAND] [ARG...]
+
+Create and run a new container from an image
+
+Aliases:
+  docker container run, docker run
+
+Options:
+      --add-host list                  Add a custom host-to-IP mapping (host:ip)
+      --blkio-weight uint16            Block IO (relative weight), between 10 and 1000, or 0 to disable (default 0)
+      --cpus decimal                   Number of CPUs
+  -e, --env list                       Set environment variables
+      --gpus gpu-request               GPU devices to add to the container ('all' to pass all GPUs)
+      --health-interval duration       Time between running the check (ms|s|m|h) (default 0s)
+      --log-driver string              Logging driver for the container
+      --log-opt list                   Log driver options
+      --pull string                    Pull image before running ("always", "missing", "never") (default "missing")
+      --restart string                 Restart policy to apply when a container exits (default "no")
+      --rm                             Automatically remove the container when it exits
+      --sig-proxy                      Proxy received signals to the process (default true)
+      --storage-opt list               Storage driver options for the container
+      --sysctl map                     Sysctl options (default map[])
+      --ulimit ulimit                  Ulimit options (default [])
+`
+
+//rootHelp : Part of docker --help of docker 24
+const rootHelp = `
+Usage:  docker [OPTIONS] COMMAND
+
+A self-sufficient runtime for containers
+
+Common Commands:
+  run         Create and run a new container from an image
+  ps          List containers
+
+Management Commands:
+  builder     Manage builds
+  compose*    Docker Compose (Docker Inc., v2.21.0)
+
+Global Options:
+      --config string      Location of client config files (default "/root/.docker")
+  -D, --debug              Enable debug mode
+
+Invalid Plugins:
+  broken      failed to fetch metadata
+`
+
+func TestParseHelpFlags(t *testing.T) {
+	page := parseHelp(psHelp)
+
+	want := []Flag{
+		{Name: "--all", Short: "-a", Description: "Show all containers (default shows just running)"},
+		{Name: "--filter", Short: "-f", Type: "filter", Description: "Filter output based on conditions provided"},
+		{Name: "--format", Type: "string", Description: "Format output using a custom template:" +
+			" 'table':            Print output in table format with column headers (default)" +
+			" 'table TEMPLATE':   Print output in table format using the given Go template" +
+			" 'json':             Print in JSON format"},
+		{Name: "--last", Short: "-n", Type: "int", Default: "-1", Description: "Show n last created containers (includes all states)"},
+		{Name: "--latest", Short: "-l", Description: "Show the latest created container (includes all states)"},
+		{Name: "--no-trunc", Description: "Don't truncate output"},
+		{Name: "--quiet", Short: "-q", Description: "Only display container IDs"},
+		{Name: "--size", Short: "-s", Description: "Display total file sizes"},
+	}
+	if !reflect.DeepEqual(page.flags, want) {
+		t.Errorf("flags =\n%+v\nwant\n%+v", page.flags, want)
+	}
+	if page.usage != "docker ps [OPTIONS]" {
+		t.Errorf("usage = %q", page.usage)
+	}
+	if want := []string{"docker container ls", "docker container list", "docker container ps", "docker ps"}; !reflect.DeepEqual(page.aliases, want) {
+		t.Errorf("aliases = %q, want %q", page.aliases, want)
+	}
+}
+
+func TestParseHelpDefaults(t *testing.T) {
+	page := parseHelp(runHelp)
+
+	tests := []struct {
+		name, flagType, value, description string
+	}{
+		{"--add-host", "list", "", "Add a custom host-to-IP mapping (host:ip)"},
+		{"--blkio-weight", "uint16", "0", "Block IO (relative weight), between 10 and 1000, or 0 to disable"},
+		{"--cpus", "decimal", "", "Number of CPUs"},
+		{"--gpus", "gpu-request", "", "GPU devices to add to the container ('all' to pass all GPUs)"},
+		{"--health-interval", "duration", "0s", "Time between running the check (ms|s|m|h)"},
+		{"--pull", "string", "missing", `Pull image before running ("always", "missing", "never")`},
+		{"--restart", "string", "no", "Restart policy to apply when a container exits"},
+		{"--rm", "", "", "Automatically remove the container when it exits"},
+		{"--sig-proxy", "", "true", "Proxy received signals to the process"},
+		{"--sysctl", "map", "map[]", "Sysctl options"},
+		{"--ulimit", "ulimit", "[]", "Ulimit options"},
+	}
+
+	flags := map[string]Flag{}
+	for _, flag := range page.flags {
+		flags[flag.Name] = flag
+	}
+	for _, tt := range tests {
+		flag, ok := flags[tt.name]
+		if !ok {
+			t.Errorf("%s was not parsed", tt.name)
+			continue
+		}
+		if flag.Type != tt.flagType || flag.Default != tt.value || flag.Description != tt.description {
+			t.Errorf("%s = type %q, default %q, %q, want type %q, default %q, %q",
+				tt.name, flag.Type, flag.Default, flag.Description, tt.flagType, tt.value, tt.description)
+		}
+	}
+}
+
+func TestParseHelpCommands(t *testing.T) {
+	page := parseHelp(rootHelp)
+
+	want := []prompt.Suggest{
+		{Text: "run", Description: "Create and run a new container from an image"},
+		{Text: "ps", Description: "List containers"},
+		{Text: "builder", Description: "Manage builds"},
+		{Text: "compose", Description: "Docker Compose (Docker Inc., v2.21.0)"},
+	}
+	if !reflect.DeepEqual(page.commands, want) {
+		t.Errorf("commands = %+v, want %+v", page.commands, want)
+	}
+}
+
+func TestInstalledVersion(t *testing.T) {
+	run := func(args ...string) (string, error) {
+		return "Docker version 24.0.7, build afdd53b\n", nil
+	}
+	if version, err := InstalledVersion(run); err != nil || version != "24.0.7" {
+		t.Errorf("InstalledVersion = %q, %v, want 24.0.7", version, err)
+	}
+}
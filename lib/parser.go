@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+//Expectation : What kind of word the cursor is expected to be on
+type Expectation int
+
+const (
+	ExpectCommand Expectation = iota
+	ExpectSubCommand
+	ExpectFlag
+	ExpectFlagValue
+	ExpectArgument
+)
+
+//Context : Result of walking the command line up to the cursor
+type Context struct {
+	//Path : Command and subcommands, e.g. ["service", "create"]
+	Path []string
+	//Flags : Flags typed before the cursor by their long names with their values, boolean flags have none
+	//and boolean flags set to false like --rm=false are left out
+	Flags map[string][]string
+	//Args : Positional arguments typed before the cursor
+	Args []string
+	//Word : The word under the cursor
+	Word string
+	//Expect : What the word under the cursor is expected to be
+	Expect Expectation
+	//Flag : The flag whose value is being typed when Expect is ExpectFlagValue
+	Flag string
+	//Node : The catalog node of Path, nil for commands the catalog does not know
+	Node *Node
+}
+
+//Command : The command path joined the same way DockerSubSuggestions is keyed
+func (ctx Context) Command() string {
+	return strings.Join(ctx.Path, " ")
+}
+
+//Canonical : The classic name of the command, see CanonicalCommand
+func (ctx Context) Canonical() string {
+	return CanonicalCommand(ctx.Command())
+}
+
+//Arg : The positional argument the word under the cursor is expected to be
+func (ctx Context) Arg() (ArgSpec, bool) {
+	if ctx.Node == nil {
+		return ArgSpec{}, false
+	}
+	return ctx.Node.Arg(ctx.ArgIndex())
+}
+
+//ArgIndex : Position of the word under the cursor among the positional arguments
+func (ctx Context) ArgIndex() int {
+	return len(ctx.Args)
+}
+
+//HasFlag : Reports whether one of the given flags was typed before the cursor
+func (ctx Context) HasFlag(names ...string) bool {
+	for _, name := range names {
+		if _, ok := ctx.Flags[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+//Parse : Walks the words of the command line into command path, flags, flag values and
+//positional arguments. The last element of words is the word under the cursor, options of
+//docker itself before the command are skipped
+func (c *Commands) Parse(words []string) Context {
+	ctx := Context{Flags: map[string][]string{}}
+	if len(words) == 0 {
+		return ctx
+	}
+
+	ctx.Word = words[len(words)-1]
+	words = words[:len(words)-1]
+	start, rootFlag := skipRootFlags(words)
+	words = words[start:]
+	if rootFlag != "" {
+		ctx.Expect, ctx.Flag = ExpectFlagValue, rootFlag
+		return ctx
+	}
+	if len(words) == 0 {
+		return ctx
+	}
+
+	rest := words[1:]
+	ctx.Path = []string{words[0]}
+	if c.Root != nil {
+		if node, remaining := c.Root.Resolve(words); node != c.Root {
+			ctx.Node, ctx.Path, rest = node, node.Path(), remaining
+		}
+	}
+
+	command := ctx.Command()
+	interspersed := !nonInterspersedCommands[CanonicalCommand(command)]
+	pendingFlag := ""
+	flagsEnded := false
+
+	for _, word := range rest {
+		switch {
+		case pendingFlag != "":
+			ctx.Flags[pendingFlag] = append(ctx.Flags[pendingFlag], word)
+			pendingFlag = ""
+		case flagsEnded || !strings.HasPrefix(word, "-") || word == "-":
+			ctx.Args = append(ctx.Args, word)
+			if !interspersed {
+				flagsEnded = true
+			}
+		case word == "--":
+			flagsEnded = true
+		default:
+			for _, flag := range c.splitFlags(command, word) {
+				name := c.LongFlag(command, flag.name)
+				boolean := !c.TakesValue(command, name)
+				if set, err := strconv.ParseBool(flag.value); flag.hasValue && boolean && err == nil {
+					if !set {
+						delete(ctx.Flags, name)
+						continue
+					}
+					flag.hasValue = false
+				}
+				if flag.hasValue {
+					ctx.Flags[name] = append(ctx.Flags[name], flag.value)
+					continue
+				}
+				if _, ok := ctx.Flags[name]; !ok {
+					ctx.Flags[name] = nil
+				}
+				if !boolean {
+					pendingFlag = name
+				}
+			}
+		}
+	}
+
+	switch {
+	case pendingFlag != "":
+		ctx.Expect = ExpectFlagValue
+		ctx.Flag = pendingFlag
+	case !flagsEnded && strings.HasPrefix(ctx.Word, "-"):
+		ctx.Expect = ExpectFlag
+	case len(ctx.Args) == 0 && len(ctx.Flags) == 0 && ctx.Node != nil && len(ctx.Node.Children) > 0:
+		ctx.Expect = ExpectSubCommand
+	default:
+		ctx.Expect = ExpectArgument
+	}
+
+	return ctx
+}
+
+func splitFlag(word string) (name, value string, hasValue bool) {
+	if i := strings.Index(word, "="); i > 0 {
+		return word[:i], word[i+1:], true
+	}
+	return word, "", false
+}
+
+//GetSubCommandSuggestions : Subcommands of the given command path
+func (c *Commands) GetSubCommandSuggestions(command string) []prompt.Suggest {
+	if node := c.Lookup(command); node != nil {
+		return c.gateCommands(node, node.SubCommandSuggestions())
+	}
+	return []prompt.Suggest{}
+}
+
+//GetFlagSuggestions : Flags of the given command path
+func (c *Commands) GetFlagSuggestions(command string) []prompt.Suggest {
+	if node := c.Lookup(command); node != nil {
+		return c.gateFlags(node, node.FlagSuggestions())
+	}
+	return []prompt.Suggest{}
+}
+
+//GetShortFlagSuggestions : Completes a bundle of short flags like -it, every suggestion adds one
+//more short flag to the bundle until a flag that takes a value ends it
+func (c *Commands) GetShortFlagSuggestions(command, word string) []prompt.Suggest {
+	node := c.Lookup(command)
+	if node == nil || !isShortFlag(word) {
+		return []prompt.Suggest{}
+	}
+
+	names := []string{}
+	last := Flag{}
+	for i := 1; i < len(word); i++ {
+		flag, ok := node.Flag("-" + word[i:i+1])
+		if !ok || last.Type != "" {
+			return []prompt.Suggest{}
+		}
+		names, last = append(names, flag.Name), flag
+	}
+
+	description := strings.Join(names, " ")
+	if len(names) == 1 {
+		description += ": " + last.Description
+	}
+	result := []prompt.Suggest{{Text: word, Description: description}}
+	if last.Type != "" {
+		return result
+	}
+
+	for _, flag := range node.Flags {
+		if flag.Short == "" || strings.Contains(word[1:], flag.Short[1:]) {
+			continue
+		}
+		result = append(result, prompt.Suggest{Text: word + flag.Short[1:], Description: flag.Name + ": " + flag.Description})
+	}
+	return c.gateFlags(node, result)
+}
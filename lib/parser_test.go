@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	catalog := Builtin()
+
+	tests := []struct {
+		line   string
+		path   string
+		flags  map[string][]string
+		args   []string
+		word   string
+		expect Expectation
+		flag   string
+	}{
+		{line: "", expect: ExpectCommand},
+		{line: "p", word: "p", expect: ExpectCommand},
+		{line: "ps ", path: "ps", expect: ExpectArgument},
+		{line: "ps -", path: "ps", word: "-", expect: ExpectFlag},
+		{line: "ps -a -f ", path: "ps", flags: map[string][]string{"--all": nil, "--filter": nil}, expect: ExpectFlagValue, flag: "--filter"},
+		{line: "ps --filter status=exited ", path: "ps", flags: map[string][]string{"--filter": {"status=exited"}}, expect: ExpectArgument},
+		{line: "container ls --all ", path: "container ls", flags: map[string][]string{"--all": nil}, expect: ExpectArgument},
+		{line: "container ", path: "container", expect: ExpectSubCommand},
+		{line: "run -it --rm alpine sh -c ", path: "run", flags: map[string][]string{"--interactive": nil, "--tty": nil, "--rm": nil}, args: []string{"alpine", "sh", "-c"}, expect: ExpectArgument},
+		{line: "run -p8080:80 --name=web -e A=b nginx", path: "run", flags: map[string][]string{"--publish": {"8080:80"}, "--name": {"web"}, "--env": {"A=b"}}, word: "nginx", expect: ExpectArgument},
+		{line: "run --rm --restart ", path: "run", flags: map[string][]string{"--rm": nil, "--restart": nil}, expect: ExpectFlagValue, flag: "--restart"},
+		{line: "run --rm=true --interactive=false alpine ", path: "run", flags: map[string][]string{"--rm": nil}, args: []string{"alpine"}, expect: ExpectArgument},
+		{line: "start -i --attach=0 web ", path: "start", flags: map[string][]string{"--interactive": nil}, args: []string{"web"}, expect: ExpectArgument},
+		{line: "run --name=false alpine ", path: "run", flags: map[string][]string{"--name": {"false"}}, args: []string{"alpine"}, expect: ExpectArgument},
+		{line: "rm -- -f ", path: "rm", args: []string{"-f"}, expect: ExpectArgument},
+		{line: "--debug ps -", path: "ps", word: "-", expect: ExpectFlag},
+		{line: "-D ps -a ", path: "ps", flags: map[string][]string{"--all": nil}, expect: ExpectArgument},
+		{line: "-H tcp://x ps ", path: "ps", expect: ExpectArgument},
+		{line: "--host=tcp://x ps ", path: "ps", expect: ExpectArgument},
+		{line: "-Htcp://x ps ", path: "ps", expect: ExpectArgument},
+		{line: "--context foo run -d nginx ", path: "run", flags: map[string][]string{"--detach": nil}, args: []string{"nginx"}, expect: ExpectArgument},
+		{line: "-D -c foo --tls container ", path: "container", expect: ExpectSubCommand},
+		{line: "--context ", expect: ExpectFlagValue, flag: "--context"},
+		{line: "-D -H ", expect: ExpectFlagValue, flag: "--host"},
+		{line: "--debug ", expect: ExpectCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			words := strings.Fields(tt.line)
+			if tt.word == "" {
+				words = append(words, "")
+			}
+			ctx := catalog.Parse(words)
+
+			flags := tt.flags
+			if flags == nil {
+				flags = map[string][]string{}
+			}
+			if ctx.Command() != tt.path {
+				t.Errorf("command = %q, want %q", ctx.Command(), tt.path)
+			}
+			if !reflect.DeepEqual(ctx.Flags, flags) {
+				t.Errorf("flags = %v, want %v", ctx.Flags, flags)
+			}
+			if !reflect.DeepEqual(ctx.Args, tt.args) {
+				t.Errorf("args = %q, want %q", ctx.Args, tt.args)
+			}
+			if ctx.Word != tt.word || ctx.Expect != tt.expect || ctx.Flag != tt.flag {
+				t.Errorf("word, expect, flag = %q, %d, %q, want %q, %d, %q", ctx.Word, ctx.Expect, ctx.Flag, tt.word, tt.expect, tt.flag)
+			}
+		})
+	}
+}
+
+func TestParseResolvesCatalogNode(t *testing.T) {
+	catalog := Builtin()
+
+	ctx := catalog.Parse([]string{"--debug", "container", "ls", ""})
+	if ctx.Node == nil || ctx.Node.Command() != "container ls" {
+		t.Fatalf("node = %+v, want container ls", ctx.Node)
+	}
+	if ctx.Canonical() != "ps" {
+		t.Errorf("canonical = %q, want ps", ctx.Canonical())
+	}
+}
@@ -0,0 +1,156 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+//IndexServer : The key docker login stores Docker Hub credentials under
+const IndexServer = "https://index.docker.io/v1/"
+
+//Credentials : What docker login stored for a registry, a username with a password or an identity token
+type Credentials struct {
+	Username      string
+	Password      string
+	IdentityToken string
+}
+
+//Empty : Reports whether there is nothing to log in with, requests stay anonymous then
+func (c Credentials) Empty() bool {
+	return c.Username == "" && c.Password == "" && c.IdentityToken == ""
+}
+
+//Config : The parts of the docker CLI config telling where the credentials of a registry are
+type Config struct {
+	Auths       map[string]authEntry `json:"auths"`
+	CredsStore  string               `json:"credsStore"`
+	CredHelpers map[string]string    `json:"credHelpers"`
+}
+
+type authEntry struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+//LoadConfig : Reads the docker CLI config at path, a missing file is an empty config
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	return config, json.Unmarshal(content, config)
+}
+
+//Hosts : Registries the config knows credentials for, Docker Hub excluded
+func (c *Config) Hosts() []string {
+	hosts := []string{}
+	seen := map[string]bool{}
+	add := func(server string) {
+		host := serverHost(server)
+		if !IsHub(host) && !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+
+	for server := range c.Auths {
+		add(server)
+	}
+	for server := range c.CredHelpers {
+		add(server)
+	}
+	return hosts
+}
+
+//Credentials : The credentials of the registry host, asking the credential helper configured
+//for it or the credential store before falling back to the auths of the config itself
+func (c *Config) Credentials(ctx context.Context, host string) (Credentials, error) {
+	server := host
+	if IsHub(host) {
+		server = IndexServer
+	}
+
+	helper := c.CredHelpers[server]
+	if helper == "" {
+		helper = c.CredsStore
+	}
+	if helper != "" {
+		credentials, err := helperCredentials(ctx, helper, server)
+		if err != nil || !credentials.Empty() {
+			return credentials, err
+		}
+	}
+
+	for key, entry := range c.Auths {
+		if key == server || serverHost(key) == host || (IsHub(host) && IsHub(serverHost(key))) {
+			return entry.credentials()
+		}
+	}
+	return Credentials{}, nil
+}
+
+func (e authEntry) credentials() (Credentials, error) {
+	credentials := Credentials{Username: e.Username, Password: e.Password, IdentityToken: e.IdentityToken}
+	if e.Auth == "" {
+		return credentials, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(e.Auth)
+	if err != nil {
+		return credentials, err
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return credentials, errors.New("invalid auth in docker config")
+	}
+	credentials.Username, credentials.Password = parts[0], strings.Trim(parts[1], "\x00")
+	return credentials, nil
+}
+
+//helperCredentials : Runs docker-credential-<helper> get the way the docker CLI does, the server
+//goes to its input and the credentials come back as json. A server the helper knows
+//nothing about has no credentials
+func helperCredentials(ctx context.Context, helper, server string) (Credentials, error) {
+	command := exec.CommandContext(ctx, "docker-credential-"+helper, "get")
+	command.Stdin = strings.NewReader(server)
+	output, err := command.Output()
+	if err != nil {
+		if bytes.Contains(output, []byte("credentials not found")) {
+			return Credentials{}, nil
+		}
+		return Credentials{}, err
+	}
+
+	response := struct {
+		Username string
+		Secret   string
+	}{}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return Credentials{}, err
+	}
+
+	if response.Username == "<token>" {
+		return Credentials{IdentityToken: response.Secret}, nil
+	}
+	return Credentials{Username: response.Username, Password: response.Secret}, nil
+}
+
+//serverHost : The host of a server address like https://registry.corp:5000/v1/
+func serverHost(server string) string {
+	server = strings.TrimPrefix(server, "https://")
+	server = strings.TrimPrefix(server, "http://")
+	return strings.SplitN(server, "/", 2)[0]
+}
@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mstrYoda/docker-shell/lib/registry/registrytest"
+)
+
+func TestHelperCredentials(t *testing.T) {
+	defer registrytest.InstallFakeHelper(t)()
+
+	tests := []struct {
+		server string
+		want   Credentials
+	}{
+		{"reg.corp", Credentials{Username: "bob", Password: "pw"}},
+		{"token.corp", Credentials{IdentityToken: "refresh"}},
+		{"unknown.corp", Credentials{}},
+	}
+
+	for _, tt := range tests {
+		credentials, err := helperCredentials(context.Background(), "fake", tt.server)
+		if err != nil {
+			t.Errorf("helperCredentials(%s) error = %v", tt.server, err)
+		}
+		if credentials != tt.want {
+			t.Errorf("helperCredentials(%s) = %+v, want %+v", tt.server, credentials, tt.want)
+		}
+	}
+
+	if _, err := helperCredentials(context.Background(), "missing", "reg.corp"); err == nil {
+		t.Error("a helper that is not installed returned no error")
+	}
+}
+
+func TestConfigCredentials(t *testing.T) {
+	defer registrytest.InstallFakeHelper(t)()
+
+	config := &Config{
+		Auths: map[string]authEntry{
+			"other.corp":      {Auth: "YWxpY2U6c2VjcmV0"},
+			"unknown.corp":    {Username: "carol", Password: "pw"},
+			"plain.corp:5000": {Username: "dave", Password: "pw"},
+		},
+		CredsStore:  "fake",
+		CredHelpers: map[string]string{"plain.corp:5000": "missing"},
+	}
+
+	tests := []struct {
+		host string
+		want Credentials
+	}{
+		{"reg.corp", Credentials{Username: "bob", Password: "pw"}},
+		{"docker.io", Credentials{Username: "hubuser", Password: "hubpw"}},
+		{"other.corp", Credentials{Username: "alice", Password: "secret"}},
+		{"unknown.corp", Credentials{Username: "carol", Password: "pw"}},
+		{"nowhere.corp", Credentials{}},
+	}
+
+	for _, tt := range tests {
+		credentials, err := config.Credentials(context.Background(), tt.host)
+		if err != nil {
+			t.Errorf("Credentials(%s) error = %v", tt.host, err)
+		}
+		if credentials != tt.want {
+			t.Errorf("Credentials(%s) = %+v, want %+v", tt.host, credentials, tt.want)
+		}
+	}
+
+	if _, err := config.Credentials(context.Background(), "plain.corp:5000"); err == nil {
+		t.Error("a failing credential helper was ignored")
+	}
+}
@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+//HubLoginURL : Endpoint of the Docker Hub API exchanging a login for a bearer token
+const HubLoginURL = "https://hub.docker.com/v2/users/login"
+
+//HubAuthURL : Auth server of Docker Hub, it exchanges identity tokens for bearer tokens
+const HubAuthURL = "https://auth.docker.io/token"
+
+//HubToken : Exchanges the Docker Hub login for a bearer token of the Docker Hub API, which
+//lists private repositories and has higher rate limits than anonymous calls
+func HubToken(ctx context.Context, client *http.Client, loginURL string, credentials Credentials) (string, error) {
+	if credentials.Username == "" || credentials.Password == "" {
+		return "", errors.New("docker hub api needs a username and password")
+	}
+
+	body, err := json.Marshal(map[string]string{"username": credentials.Username, "password": credentials.Password})
+	if err != nil {
+		return "", err
+	}
+	request, err := http.NewRequest(http.MethodPost, loginURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("docker hub login responded %s", response.Status)
+	}
+
+	login := struct {
+		Token string `json:"token"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&login); err != nil {
+		return "", err
+	}
+	if login.Token == "" {
+		return "", errors.New("docker hub login returned no token")
+	}
+	return login.Token, nil
+}
+
+//HubIdentityToken : Exchanges the identity token docker login stored for Docker Hub, e.g. by
+//logging in through Docker Desktop, for a bearer token the same way registries are logged into
+func HubIdentityToken(ctx context.Context, client *http.Client, authURL string, credentials Credentials) (string, error) {
+	if credentials.IdentityToken == "" {
+		return "", errors.New("docker hub login has no identity token")
+	}
+
+	hub := &Client{HTTPClient: client, Credentials: credentials}
+	token, err := hub.fetchToken(ctx, authURL, "registry.docker.io", "")
+	if err == nil && token == "" {
+		err = errors.New("docker hub auth returned no token")
+	}
+	return token, err
+}
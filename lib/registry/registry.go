@@ -0,0 +1,370 @@
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+//HubURL : Registry endpoint of Docker Hub
+const HubURL = "https://registry-1.docker.io"
+
+//ManifestListTypes : Media types of manifest lists, they hold one manifest per platform
+var ManifestListTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+}
+
+var (
+	challengeExpression = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	nextExpression      = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+//Client : Talks to a registry through the Registry HTTP API v2, with basic auth or bearer
+//tokens obtained from the auth server the registry points to
+type Client struct {
+	//BaseURL : Registry endpoint without path, e.g. https://registry-1.docker.io
+	BaseURL    string
+	HTTPClient *http.Client
+	//PageSize : Tags or repositories asked for per request, the registry may return fewer
+	PageSize int
+	//Credentials : Used once the registry asks for them, requests are anonymous when empty
+	Credentials Credentials
+	//Insecure : The certificate of the registry is not verified and plain http is
+	//tried when https fails, like the daemon does for insecure registries
+	Insecure bool
+
+	lock   sync.Mutex
+	tokens map[string]string
+}
+
+//Manifest : A platform specific manifest of a manifest list
+type Manifest struct {
+	Digest   string
+	Platform string
+}
+
+//New : A client of the registry at baseURL with a short timeout, completions must not hang
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+		PageSize:   100,
+		tokens:     map[string]string{},
+	}
+}
+
+//NewInsecure : A client of a registry the daemon is configured to treat as insecure
+func NewInsecure(baseURL string) *Client {
+	c := New(baseURL)
+	c.Insecure = true
+	c.HTTPClient.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	return c
+}
+
+//SplitHost : Splits an image name into its registry host and repository, the host is empty
+//for Docker Hub images. Like docker, the first component is a host only when it has a dot,
+//a port or is localhost
+func SplitHost(name string) (string, string) {
+	i := strings.Index(name, "/")
+	if i <= 0 {
+		return "", name
+	}
+
+	host := name[:i]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return "", name
+	}
+	if IsHub(host) {
+		return "", name[i+1:]
+	}
+	return host, name[i+1:]
+}
+
+//IsHub : Reports whether the host is one of the names of Docker Hub
+func IsHub(host string) bool {
+	switch host {
+	case "docker.io", "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return true
+	}
+	return false
+}
+
+//HubRepository : The repository name Docker Hub knows an image by, official images live under library/
+func HubRepository(name string) string {
+	_, name = SplitHost(name)
+	if !strings.Contains(name, "/") {
+		return "library/" + name
+	}
+	return name
+}
+
+//Catalog : Every repository of the registry, following the pagination of the registry.
+//Docker Hub does not serve its catalog
+func (c *Client) Catalog(ctx context.Context) ([]string, error) {
+	first := fmt.Sprintf("%s/v2/_catalog?n=%d", c.BaseURL, c.PageSize)
+	return c.list(ctx, first, "registry:catalog:*", "repositories")
+}
+
+//Tags : Every tag of the repository, following the pagination of the registry
+func (c *Client) Tags(ctx context.Context, repository string) ([]string, error) {
+	first := fmt.Sprintf("%s/v2/%s/tags/list?n=%d", c.BaseURL, repository, c.PageSize)
+	return c.list(ctx, first, pullScope(repository), "tags")
+}
+
+//list : Collects the list under key of every page, the Link header of a page points to the next one
+func (c *Client) list(ctx context.Context, next, scope, key string) ([]string, error) {
+	items := []string{}
+	for next != "" {
+		response, err := c.get(ctx, next, scope, nil)
+		if err != nil {
+			return items, err
+		}
+
+		page := map[string]json.RawMessage{}
+		err = json.NewDecoder(response.Body).Decode(&page)
+		link := response.Header.Get("Link")
+		response.Body.Close()
+		if err != nil {
+			return items, err
+		}
+		if list, ok := page[key]; ok {
+			values := []string{}
+			if err := json.Unmarshal(list, &values); err != nil {
+				return items, err
+			}
+			items = append(items, values...)
+		}
+
+		next = ""
+		if match := nextExpression.FindStringSubmatch(link); match != nil {
+			if next, err = c.resolve(match[1]); err != nil {
+				return items, err
+			}
+		}
+	}
+	return items, nil
+}
+
+//Manifests : Digest of the manifest list of the reference with the digests of its platform manifests,
+//images with a single manifest only have their own digest
+func (c *Client) Manifests(ctx context.Context, repository, reference string) (string, []Manifest, error) {
+	endpoint := fmt.Sprintf("%s/v2/%s/manifests/%s", c.BaseURL, repository, reference)
+	accept := append(append([]string{}, ManifestListTypes...),
+		"application/vnd.docker.distribution.manifest.v2+json",
+		"application/vnd.oci.image.manifest.v1+json",
+	)
+
+	response, err := c.get(ctx, endpoint, pullScope(repository), map[string]string{"Accept": strings.Join(accept, ", ")})
+	if err != nil {
+		return "", nil, err
+	}
+	defer response.Body.Close()
+
+	list := struct {
+		Manifests []struct {
+			Digest   string `json:"digest"`
+			Platform struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+				Variant      string `json:"variant"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&list); err != nil {
+		return "", nil, err
+	}
+
+	manifests := []Manifest{}
+	for _, m := range list.Manifests {
+		platform := m.Platform.OS + "/" + m.Platform.Architecture
+		if m.Platform.Variant != "" {
+			platform += "/" + m.Platform.Variant
+		}
+		manifests = append(manifests, Manifest{Digest: m.Digest, Platform: platform})
+	}
+	return response.Header.Get("Docker-Content-Digest"), manifests, nil
+}
+
+//get : Requests the url with the authorization of the scope, a 401 challenge is answered
+//by authorizing the way it asks for and trying once more
+func (c *Client) get(ctx context.Context, endpoint, scope string, headers map[string]string) (*http.Response, error) {
+	response, err := c.do(ctx, endpoint, c.token(scope), headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusUnauthorized {
+		challenge := response.Header.Get("WWW-Authenticate")
+		response.Body.Close()
+
+		authorization, err := c.authorize(ctx, challenge, scope)
+		if err != nil {
+			return nil, err
+		}
+		if response, err = c.do(ctx, endpoint, authorization, headers); err != nil {
+			return nil, err
+		}
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("registry responded %s to %s", response.Status, endpoint)
+	}
+	return response, nil
+}
+
+//do : Sends the request with the Authorization header given, an insecure registry
+//is asked once more over plain http when https fails
+func (c *Client) do(ctx context.Context, endpoint, authorization string, headers map[string]string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	response, err := c.HTTPClient.Do(request.WithContext(ctx))
+	if err != nil && c.Insecure && request.URL.Scheme == "https" && ctx.Err() == nil {
+		request.URL.Scheme = "http"
+		return c.HTTPClient.Do(request.WithContext(ctx))
+	}
+	return response, err
+}
+
+//authorize : Answers a challenge, Basic ones with the credentials and Bearer ones with a token
+//for the scope fetched from the auth server named in the challenge like
+//Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"
+func (c *Client) authorize(ctx context.Context, challenge, scope string) (string, error) {
+	scheme := strings.ToLower(strings.SplitN(challenge, " ", 2)[0])
+	switch {
+	case scheme == "basic" && c.Credentials.Username != "":
+		authorization := "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Credentials.Username+":"+c.Credentials.Password))
+		c.setToken(scope, authorization)
+		return authorization, nil
+	case scheme == "basic":
+		return "", errors.New("registry requires a login, run docker login first")
+	case scheme != "bearer":
+		return "", errors.New("registry requires unsupported authentication: " + challenge)
+	}
+
+	params := map[string]string{}
+	for _, match := range challengeExpression.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", errors.New("registry challenge without realm: " + challenge)
+	}
+	if params["scope"] == "" {
+		params["scope"] = scope
+	}
+
+	token, err := c.fetchToken(ctx, params["realm"], params["service"], params["scope"])
+	if err != nil {
+		return "", err
+	}
+	c.setToken(scope, "Bearer "+token)
+	return "Bearer " + token, nil
+}
+
+//fetchToken : Asks the auth server for a token of the scope, identity tokens are exchanged
+//through OAuth2 while usernames and passwords go along as basic auth
+func (c *Client) fetchToken(ctx context.Context, realm, service, scope string) (string, error) {
+	query := url.Values{}
+	query.Set("scope", scope)
+	if service != "" {
+		query.Set("service", service)
+	}
+
+	var request *http.Request
+	var err error
+	if c.Credentials.IdentityToken != "" {
+		query.Set("grant_type", "refresh_token")
+		query.Set("refresh_token", c.Credentials.IdentityToken)
+		query.Set("client_id", "docker-shell")
+		request, err = http.NewRequest(http.MethodPost, realm, strings.NewReader(query.Encode()))
+		if err == nil {
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		request, err = http.NewRequest(http.MethodGet, realm+"?"+query.Encode(), nil)
+		if err == nil && c.Credentials.Username != "" {
+			request.SetBasicAuth(c.Credentials.Username, c.Credentials.Password)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	response, err := c.HTTPClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		return "", fmt.Errorf("auth server responded %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return token.Token, nil
+}
+
+//token : The Authorization header that worked for the scope before
+func (c *Client) token(scope string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.tokens[scope]
+}
+
+func (c *Client) setToken(scope, authorization string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.tokens[scope] = authorization
+}
+
+func pullScope(repository string) string {
+	return "repository:" + repository + ":pull"
+}
+
+//resolve : Registries return the next page as a path relative to their endpoint
+func (c *Client) resolve(reference string) (string, error) {
+	base, err := url.Parse(c.BaseURL + "/")
+	if err != nil {
+		return "", err
+	}
+	next, err := base.Parse(reference)
+	if err != nil {
+		return "", err
+	}
+	return next.String(), nil
+}
@@ -0,0 +1,217 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTagsWithBearerChallengeAndPagination(t *testing.T) {
+	var server *httptest.Server
+	tokenRequests := 0
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			tokenRequests++
+			if r.URL.Query().Get("scope") != "repository:library/nginx:pull" || r.URL.Query().Get("service") != "test" {
+				t.Errorf("token asked for %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"token":"secret"}`)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test",scope="repository:library/nginx:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/v2/library/nginx/tags/list" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/library/nginx/tags/list?n=2&last=b>; rel="next"`)
+			fmt.Fprint(w, `{"name":"library/nginx","tags":["alpine","1.25"]}`)
+			return
+		}
+		fmt.Fprint(w, `{"name":"library/nginx","tags":["latest","1.9"]}`)
+	}))
+	defer server.Close()
+
+	client := New(server.URL)
+	tags, err := client.Tags(context.Background(), "library/nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alpine", "1.25", "latest", "1.9"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("Tags = %v, want %v", tags, want)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("asked for %d tokens, want 1 reused for every page", tokenRequests)
+	}
+}
+
+func TestCatalogWithBasicChallengeAndPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "bob" || password != "pw" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/_catalog?n=1&last=team%2Fapi>; rel="next"`)
+			fmt.Fprint(w, `{"repositories":["team/api"]}`)
+			return
+		}
+		fmt.Fprint(w, `{"repositories":["team/web"]}`)
+	}))
+	defer server.Close()
+
+	anonymous := New(server.URL)
+	if _, err := anonymous.Catalog(context.Background()); err == nil {
+		t.Error("Catalog without credentials succeeded against a registry asking for basic auth")
+	}
+
+	client := New(server.URL)
+	client.Credentials = Credentials{Username: "bob", Password: "pw"}
+	repositories, err := client.Catalog(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"team/api", "team/web"}; !reflect.DeepEqual(repositories, want) {
+		t.Errorf("Catalog = %v, want %v", repositories, want)
+	}
+}
+
+func TestManifests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), ManifestListTypes[0]) {
+			t.Errorf("manifest asked without accepting manifest lists: %s", r.Header.Get("Accept"))
+		}
+		switch r.URL.Path {
+		case "/v2/library/nginx/manifests/latest":
+			w.Header().Set("Docker-Content-Digest", "sha256:list")
+			fmt.Fprint(w, `{"mediaType":"`+ManifestListTypes[0]+`","manifests":[
+				{"digest":"sha256:amd64","platform":{"os":"linux","architecture":"amd64"}},
+				{"digest":"sha256:arm","platform":{"os":"linux","architecture":"arm","variant":"v7"}}]}`)
+		case "/v2/team/api/manifests/1.0":
+			w.Header().Set("Docker-Content-Digest", "sha256:single")
+			fmt.Fprint(w, `{"mediaType":"application/vnd.docker.distribution.manifest.v2+json","layers":[]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := New(server.URL)
+	digest, manifests, err := client.Manifests(context.Background(), "library/nginx", "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Manifest{{Digest: "sha256:amd64", Platform: "linux/amd64"}, {Digest: "sha256:arm", Platform: "linux/arm/v7"}}
+	if digest != "sha256:list" || !reflect.DeepEqual(manifests, want) {
+		t.Errorf("Manifests = %s, %v, want sha256:list, %v", digest, manifests, want)
+	}
+
+	digest, manifests, err = client.Manifests(context.Background(), "team/api", "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != "sha256:single" || len(manifests) != 0 {
+		t.Errorf("Manifests = %s, %v, want sha256:single without platform manifests", digest, manifests)
+	}
+
+	if _, _, err := client.Manifests(context.Background(), "team/missing", "latest"); err == nil {
+		t.Error("Manifests of a missing repository succeeded")
+	}
+}
+
+func TestInsecureFallsBackToHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tags":["1.0"]}`)
+	}))
+	defer server.Close()
+	endpoint := strings.Replace(server.URL, "http://", "https://", 1)
+
+	if _, err := New(endpoint).Tags(context.Background(), "team/api"); err == nil {
+		t.Error("a secure client fell back to plain http")
+	}
+
+	tags, err := NewInsecure(endpoint).Tags(context.Background(), "team/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1.0"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("Tags = %v, want %v", tags, want)
+	}
+}
+
+func TestSplitHost(t *testing.T) {
+	tests := []struct {
+		name, host, repository string
+	}{
+		{"nginx", "", "nginx"},
+		{"team/api", "", "team/api"},
+		{"docker.io/library/nginx", "", "library/nginx"},
+		{"registry.corp:5000/team/api", "registry.corp:5000", "team/api"},
+		{"localhost/api", "localhost", "api"},
+	}
+
+	for _, tt := range tests {
+		if host, repository := SplitHost(tt.name); host != tt.host || repository != tt.repository {
+			t.Errorf("SplitHost(%q) = %q, %q, want %q, %q", tt.name, host, repository, tt.host, tt.repository)
+		}
+	}
+	if repository := HubRepository("nginx"); repository != "library/nginx" {
+		t.Errorf("HubRepository(nginx) = %q, want library/nginx", repository)
+	}
+}
+
+func TestSortTags(t *testing.T) {
+	tags := []string{"alpine", "1.9", "1.25-alpine", "latest", "1.25", "v2.0.1", "1.25.3", "edge", "mainline"}
+	SortTags(tags)
+
+	want := []string{"latest", "v2.0.1", "1.25.3", "1.25", "1.25-alpine", "1.9", "alpine", "edge", "mainline"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("SortTags = %v, want %v", tags, want)
+	}
+}
+
+func TestHubTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			login := map[string]string{}
+			if err := json.NewDecoder(r.Body).Decode(&login); err != nil || login["username"] != "bob" || login["password"] != "pw" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"token":"from-login"}`)
+		case "/token":
+			if r.Method != http.MethodPost || r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"from-identity"}`)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	if token, err := HubToken(ctx, server.Client(), server.URL+"/login", Credentials{Username: "bob", Password: "pw"}); err != nil || token != "from-login" {
+		t.Errorf("HubToken = %q, %v, want from-login", token, err)
+	}
+	if _, err := HubToken(ctx, server.Client(), server.URL+"/login", Credentials{Username: "bob", Password: "wrong"}); err == nil {
+		t.Error("HubToken with a wrong password succeeded")
+	}
+	if token, err := HubIdentityToken(ctx, server.Client(), server.URL+"/token", Credentials{IdentityToken: "refresh"}); err != nil || token != "from-identity" {
+		t.Errorf("HubIdentityToken = %q, %v, want from-identity", token, err)
+	}
+	if _, err := HubIdentityToken(ctx, server.Client(), server.URL+"/token", Credentials{Username: "bob", Password: "pw"}); err == nil {
+		t.Error("HubIdentityToken without an identity token succeeded")
+	}
+}
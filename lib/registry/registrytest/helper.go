@@ -0,0 +1,47 @@
+//registrytest : Fixtures for tests of code reading docker credentials
+package registrytest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+//FakeHelper : docker-credential-fake, reading the server from its input like the real helpers.
+//It knows bob for reg.corp, an identity token for token.corp and hubuser for Docker Hub
+const FakeHelper = `#!/bin/sh
+[ "$1" = get ] || exit 2
+read server
+case "$server" in
+reg.corp) echo '{"ServerURL":"reg.corp","Username":"bob","Secret":"pw"}' ;;
+token.corp) echo '{"ServerURL":"token.corp","Username":"<token>","Secret":"refresh"}' ;;
+https://index.docker.io/v1/) echo '{"ServerURL":"https://index.docker.io/v1/","Username":"hubuser","Secret":"hubpw"}' ;;
+*) echo "credentials not found in native keychain"; exit 1 ;;
+esac
+`
+
+//InstallFakeHelper : Puts docker-credential-fake first on PATH until the returned func runs.
+//Tests calling it are skipped on windows, the helper is a shell script
+func InstallFakeHelper(t *testing.T) func() {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helper is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "credential-helper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "docker-credential-fake"), []byte(FakeHelper), 0755); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	return func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//versionExpression : Tags starting with a version like 1.25, v2.0.1 or 3.18.4-alpine
+var versionExpression = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)(.*)$`)
+
+//SortTags : Orders tags the way people look for them: latest first, then versions from the
+//newest down, plain versions before their variants, and everything else alphabetically
+func SortTags(tags []string) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tagLess(tags[i], tags[j])
+	})
+}
+
+func tagLess(a, b string) bool {
+	if a == "latest" || b == "latest" {
+		return a == "latest" && b != "latest"
+	}
+
+	va, sa, aok := parseVersion(a)
+	vb, sb, bok := parseVersion(b)
+	switch {
+	case aok != bok:
+		return aok
+	case !aok:
+		return a < b
+	}
+
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		switch {
+		case i >= len(va):
+			return false
+		case i >= len(vb):
+			return true
+		case va[i] != vb[i]:
+			return va[i] > vb[i]
+		}
+	}
+
+	if (sa == "") != (sb == "") {
+		return sa == ""
+	}
+	return sa < sb
+}
+
+//parseVersion : The numeric components of a version tag and what follows them
+func parseVersion(tag string) ([]int, string, bool) {
+	match := versionExpression.FindStringSubmatch(tag)
+	if match == nil {
+		return nil, "", false
+	}
+
+	version := []int{}
+	for _, part := range strings.Split(match[1], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, "", false
+		}
+		version = append(version, n)
+	}
+	return version, match[2], true
+}
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+//Requirement : What the daemon has to support for a command or flag to work
+type Requirement struct {
+	//MinAPIVersion : Oldest daemon API version accepting it, e.g. "1.32"
+	MinAPIVersion string
+	//Experimental : Only available when the daemon runs with experimental features enabled
+	Experimental bool
+	//OSType : Only available on daemons of this OS, "linux" or "windows"
+	OSType string
+}
+
+//Daemon : What the connected daemon supports, as reported by ping and version
+type Daemon struct {
+	APIVersion   string
+	Version      string
+	OSType       string
+	Experimental bool
+}
+
+//commandRequirements : Requirements of the commands, keyed by their classic command path
+var commandRequirements = map[string]Requirement{
+	"builder":          {MinAPIVersion: "1.31"},
+	"builder prune":    {MinAPIVersion: "1.39"},
+	"checkpoint":       {MinAPIVersion: "1.25", Experimental: true, OSType: "linux"},
+	"config":           {MinAPIVersion: "1.30"},
+	"network prune":    {MinAPIVersion: "1.25"},
+	"node":             {MinAPIVersion: "1.24"},
+	"plugin":           {MinAPIVersion: "1.25"},
+	"prune":            {MinAPIVersion: "1.25"},
+	"image prune":      {MinAPIVersion: "1.25"},
+	"secret":           {MinAPIVersion: "1.25"},
+	"service":          {MinAPIVersion: "1.24"},
+	"service logs":     {MinAPIVersion: "1.29"},
+	"service rollback": {MinAPIVersion: "1.31"},
+	"stack":            {MinAPIVersion: "1.25"},
+	"swarm":            {MinAPIVersion: "1.24"},
+	"system":           {MinAPIVersion: "1.25"},
+	"volume prune":     {MinAPIVersion: "1.25"},
+}
+
+//flagRequirements : Requirements of flags per classic command path, the empty path holds
+//flags that have the same requirement for every command
+var flagRequirements = map[string]map[string]Requirement{
+	"": {
+		"--platform": {MinAPIVersion: "1.32"},
+	},
+	"build": {
+		"--squash": {MinAPIVersion: "1.25", Experimental: true},
+		"--target": {MinAPIVersion: "1.29"},
+	},
+	"create": runFlagRequirements,
+	"run":    runFlagRequirements,
+	"start": {
+		"--checkpoint":     {MinAPIVersion: "1.25", Experimental: true, OSType: "linux"},
+		"--checkpoint-dir": {MinAPIVersion: "1.25", Experimental: true, OSType: "linux"},
+	},
+	"update": {
+		"--cpus":       {MinAPIVersion: "1.29"},
+		"--pids-limit": {MinAPIVersion: "1.40"},
+	},
+}
+
+var runFlagRequirements = map[string]Requirement{
+	"--cgroupns":           {MinAPIVersion: "1.41", OSType: "linux"},
+	"--cpus":               {MinAPIVersion: "1.25"},
+	"--device-cgroup-rule": {MinAPIVersion: "1.28", OSType: "linux"},
+	"--gpus":               {MinAPIVersion: "1.40"},
+	"--init":               {MinAPIVersion: "1.25"},
+	"--stop-timeout":       {MinAPIVersion: "1.25"},
+}
+
+func commandRequirement(command string) Requirement {
+	if requirement, ok := commandRequirements[command]; ok {
+		return requirement
+	}
+	return commandRequirements[CanonicalCommand(command)]
+}
+
+func flagRequirement(command, flag string) Requirement {
+	if requirement, ok := flagRequirements[CanonicalCommand(command)][flag]; ok {
+		return requirement
+	}
+	return flagRequirements[""][flag]
+}
+
+//Known : Reports whether a daemon was connected, nothing is gated otherwise
+func (d Daemon) Known() bool {
+	return d.APIVersion != ""
+}
+
+//Supports : Reports whether the daemon can run what has the requirement, experimental
+//features count as supported since the daemon may be restarted with them enabled
+func (d Daemon) Supports(r Requirement) bool {
+	if !d.Known() {
+		return true
+	}
+	if r.MinAPIVersion != "" && CompareVersions(d.APIVersion, r.MinAPIVersion) < 0 {
+		return false
+	}
+	return r.OSType == "" || d.OSType == "" || r.OSType == d.OSType
+}
+
+//gate : Drops the suggestion when the daemon does not support it and marks experimental ones
+func (c *Commands) gate(s prompt.Suggest, r Requirement) (prompt.Suggest, bool) {
+	if !c.Daemon.Supports(r) {
+		return s, false
+	}
+
+	switch {
+	case r.Experimental && c.Daemon.Known() && !c.Daemon.Experimental:
+		s.Description = "(needs experimental daemon) " + s.Description
+	case r.Experimental:
+		s.Description = "(experimental) " + s.Description
+	}
+	return s, true
+}
+
+//gateCommands : Applies the daemon requirements of the subcommands of node to their suggestions
+func (c *Commands) gateCommands(node *Node, suggestions []prompt.Suggest) []prompt.Suggest {
+	result := []prompt.Suggest{}
+	for _, s := range suggestions {
+		requirement := Requirement{}
+		if child := node.Child(s.Text); child != nil {
+			requirement = child.Requires
+		}
+		if s, ok := c.gate(s, requirement); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+//gateFlags : Applies the daemon requirements of the flags of node to their suggestions, for
+//bundled short flags the flag added last is checked
+func (c *Commands) gateFlags(node *Node, suggestions []prompt.Suggest) []prompt.Suggest {
+	result := []prompt.Suggest{}
+	for _, s := range suggestions {
+		name := s.Text
+		if isShortFlag(name) {
+			name = "-" + name[len(name)-1:]
+		}
+
+		requirement := Requirement{}
+		if flag, ok := node.Flag(name); ok {
+			requirement = flag.Requires
+		}
+		if s, ok := c.gate(s, requirement); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+//CompareVersions : Compares dotted versions like API versions 1.24 and 1.40 numerically
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := 0, 0
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,269 @@
+package tokenizer
+
+import (
+	"errors"
+	"os"
+	"os/user"
+	"strings"
+)
+
+//ErrUnterminatedQuote : Returned when a quote is still open at the end of the line
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+//ErrTrailingBackslash : Returned when the line ends with an escaping backslash
+var ErrTrailingBackslash = errors.New("trailing backslash")
+
+//Token : A single word of the command line together with its position in the line
+type Token struct {
+	Value string
+	Start int
+	End   int
+}
+
+//Tokenizer : Splits command lines into words following POSIX shell quoting rules
+type Tokenizer struct {
+	Lookup func(name string) (string, bool)
+	Home   func(username string) (string, bool)
+}
+
+func New() *Tokenizer {
+	return &Tokenizer{
+		Lookup: os.LookupEnv,
+		Home:   homeDir,
+	}
+}
+
+//Split : Splits the line into expanded words
+func Split(line string) ([]string, error) {
+	return New().Split(line)
+}
+
+func (t *Tokenizer) Split(line string) ([]string, error) {
+	tokens, err := t.Tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+
+	words := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		words = append(words, token.Value)
+	}
+	return words, nil
+}
+
+//Tokenize : Splits the line into tokens. The tokens read so far are returned even
+//when the line is incomplete, so callers like the completer can work on partial input
+func (t *Tokenizer) Tokenize(line string) ([]Token, error) {
+	tokens := []Token{}
+	pos := 0
+
+	for {
+		for pos < len(line) && isBlank(line[pos]) {
+			pos++
+		}
+		if pos >= len(line) {
+			return tokens, nil
+		}
+
+		token, keep, err := t.readWord(line, pos)
+		if keep {
+			tokens = append(tokens, token)
+		}
+		if err != nil {
+			return tokens, err
+		}
+		pos = token.End
+	}
+}
+
+//readWord : Reads one word starting at pos. Words made of nothing but unquoted
+//expansions that turned out empty are dropped, like a shell does
+func (t *Tokenizer) readWord(line string, pos int) (Token, bool, error) {
+	var value strings.Builder
+	token := Token{Start: pos}
+	quoted := false
+
+	if line[pos] == '~' {
+		if home, end, ok := t.expandTilde(line, pos); ok {
+			value.WriteString(home)
+			quoted = true
+			pos = end
+		}
+	}
+
+	for pos < len(line) && !isBlank(line[pos]) {
+		switch c := line[pos]; c {
+		case '\'':
+			end := strings.IndexByte(line[pos+1:], '\'')
+			if end < 0 {
+				value.WriteString(line[pos+1:])
+				token.Value, token.End = value.String(), len(line)
+				return token, true, ErrUnterminatedQuote
+			}
+			value.WriteString(line[pos+1 : pos+1+end])
+			quoted = true
+			pos += end + 2
+		case '"':
+			end, err := t.readDoubleQuoted(line, pos+1, &value)
+			quoted = true
+			pos = end
+			if err != nil {
+				token.Value, token.End = value.String(), pos
+				return token, true, err
+			}
+		case '\\':
+			if pos+1 >= len(line) {
+				token.Value, token.End = value.String(), len(line)
+				return token, true, ErrTrailingBackslash
+			}
+			value.WriteByte(line[pos+1])
+			quoted = true
+			pos += 2
+		case '$':
+			expanded, end := t.expandVariable(line, pos)
+			value.WriteString(expanded)
+			pos = end
+		default:
+			value.WriteByte(c)
+			quoted = true
+			pos++
+		}
+	}
+
+	token.Value, token.End = value.String(), pos
+	return token, quoted || token.Value != "", nil
+}
+
+//readDoubleQuoted : Reads up to the closing double quote. Inside double quotes a
+//backslash only escapes $, `, ", \ and newline, and variables are still expanded
+func (t *Tokenizer) readDoubleQuoted(line string, pos int, value *strings.Builder) (int, error) {
+	for pos < len(line) {
+		switch c := line[pos]; c {
+		case '"':
+			return pos + 1, nil
+		case '\\':
+			if pos+1 < len(line) && strings.IndexByte("$`\"\\\n", line[pos+1]) >= 0 {
+				value.WriteByte(line[pos+1])
+				pos += 2
+				continue
+			}
+			value.WriteByte(c)
+			pos++
+		case '$':
+			expanded, end := t.expandVariable(line, pos)
+			value.WriteString(expanded)
+			pos = end
+		default:
+			value.WriteByte(c)
+			pos++
+		}
+	}
+
+	return pos, ErrUnterminatedQuote
+}
+
+//expandVariable : Expands $NAME, ${NAME} and $? at pos. A dollar sign that does
+//not start a variable reference is kept as it is
+func (t *Tokenizer) expandVariable(line string, pos int) (string, int) {
+	start := pos + 1
+	if start >= len(line) {
+		return "$", start
+	}
+
+	if line[start] == '{' {
+		end := strings.IndexByte(line[start:], '}')
+		if end < 0 {
+			return line[pos:], len(line)
+		}
+		name := line[start+1 : start+end]
+		if !isName(name) && name != "?" {
+			return line[pos : start+end+1], start + end + 1
+		}
+		return t.lookup(name), start + end + 1
+	}
+
+	if line[start] == '?' {
+		return t.lookup("?"), start + 1
+	}
+
+	end := start
+	for end < len(line) && isNameChar(line[end], end == start) {
+		end++
+	}
+	if end == start {
+		return "$", start
+	}
+	return t.lookup(line[start:end]), end
+}
+
+//expandTilde : Expands ~ and ~user at the beginning of a word
+func (t *Tokenizer) expandTilde(line string, pos int) (string, int, bool) {
+	end := pos + 1
+	for end < len(line) && line[end] != '/' && !isBlank(line[end]) {
+		end++
+	}
+
+	username := line[pos+1 : end]
+	if username != "" && !isName(strings.Replace(strings.Replace(username, "-", "_", -1), ".", "_", -1)) {
+		return "", pos, false
+	}
+	if t.Home == nil {
+		return "", pos, false
+	}
+
+	home, ok := t.Home(username)
+	if !ok {
+		return "", pos, false
+	}
+	return home, end, true
+}
+
+func (t *Tokenizer) lookup(name string) string {
+	if t.Lookup == nil {
+		return ""
+	}
+
+	value, _ := t.Lookup(name)
+	return value
+}
+
+func homeDir(username string) (string, bool) {
+	if username == "" {
+		if home, ok := os.LookupEnv("HOME"); ok && home != "" {
+			return home, true
+		}
+		if u, err := user.Current(); err == nil {
+			return u.HomeDir, true
+		}
+		return "", false
+	}
+
+	u, err := user.Lookup(username)
+	if err != nil {
+		return "", false
+	}
+	return u.HomeDir, true
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isNameChar(s[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
@@ -0,0 +1,101 @@
+package tokenizer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestTokenizer() *Tokenizer {
+	variables := map[string]string{"A": "a", "EMPTY": "", "?": "3"}
+	homes := map[string]string{"": "/home/me", "user": "/home/user"}
+
+	return &Tokenizer{
+		Lookup: func(name string) (string, bool) {
+			value, ok := variables[name]
+			return value, ok
+		},
+		Home: func(username string) (string, bool) {
+			home, ok := homes[username]
+			return home, ok
+		},
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+		err  error
+	}{
+		{name: "plain words", line: "run  -it\talpine", want: []string{"run", "-it", "alpine"}},
+		{name: "single quotes", line: `echo 'a b' '$A'`, want: []string{"echo", "a b", "$A"}},
+		{name: "unterminated single quote", line: `echo 'a b`, want: []string{"echo", "a b"}, err: ErrUnterminatedQuote},
+		{name: "unterminated double quote", line: `echo "a $A`, want: []string{"echo", "a a"}, err: ErrUnterminatedQuote},
+		{name: "trailing backslash", line: `echo a\`, want: []string{"echo", "a"}, err: ErrTrailingBackslash},
+		{name: "escaped blank", line: `echo a\ b`, want: []string{"echo", "a b"}},
+		{name: "escapes in double quotes", line: `echo "\"\$A\\"`, want: []string{"echo", `"$A\`}},
+		{name: "backslash n kept in double quotes", line: `echo "a\nb"`, want: []string{"echo", `a\nb`}},
+		{name: "empty double quotes kept", line: `echo "" x`, want: []string{"echo", "", "x"}},
+		{name: "empty single quotes kept", line: `echo ''`, want: []string{"echo", ""}},
+		{name: "unset variable dropped", line: "echo $UNSET x", want: []string{"echo", "x"}},
+		{name: "empty variable dropped", line: "echo $EMPTY", want: []string{"echo"}},
+		{name: "quoted unset variable kept", line: `echo "$UNSET"`, want: []string{"echo", ""}},
+		{name: "braced variable", line: "echo ${A}b", want: []string{"echo", "ab"}},
+		{name: "unbraced variable ends at non name", line: "echo $A-b", want: []string{"echo", "a-b"}},
+		{name: "default value left literal", line: "echo ${A:-x}", want: []string{"echo", "${A:-x}"}},
+		{name: "unterminated brace left literal", line: "echo ${A", want: []string{"echo", "${A"}},
+		{name: "exit code", line: "echo $? ${?}", want: []string{"echo", "3", "3"}},
+		{name: "lone dollar", line: "echo $ a$", want: []string{"echo", "$", "a$"}},
+		{name: "dollar before digit-less name", line: "echo $-", want: []string{"echo", "$-"}},
+		{name: "home", line: "ls ~ ~/x", want: []string{"ls", "/home/me", "/home/me/x"}},
+		{name: "home of user", line: "ls ~user/x", want: []string{"ls", "/home/user/x"}},
+		{name: "unknown user not expanded", line: "ls ~nobody/x", want: []string{"ls", "~nobody/x"}},
+		{name: "tilde inside word not expanded", line: "ls a~b", want: []string{"ls", "a~b"}},
+		{name: "quoted tilde not expanded", line: `ls "~" '~'`, want: []string{"ls", "~", "~"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := newTestTokenizer().Tokenize(tt.line)
+			if err != tt.err {
+				t.Fatalf("Tokenize(%q) error = %v, want %v", tt.line, err, tt.err)
+			}
+
+			got := []string{}
+			for _, token := range tokens {
+				got = append(got, token.Value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeOffsets(t *testing.T) {
+	tests := []struct {
+		line string
+		want []Token
+	}{
+		{line: "run -it alpine", want: []Token{{"run", 0, 3}, {"-it", 4, 7}, {"alpine", 8, 14}}},
+		{line: `  echo "a b"  'c'`, want: []Token{{"echo", 2, 6}, {"a b", 7, 12}, {"c", 14, 17}}},
+		{line: "echo $UNSET $A", want: []Token{{"echo", 0, 4}, {"a", 12, 14}}},
+		{line: "ls ~/x", want: []Token{{"ls", 0, 2}, {"/home/me/x", 3, 6}}},
+		{line: `echo 'open`, want: []Token{{"echo", 0, 4}, {"open", 5, 10}}},
+	}
+
+	for _, tt := range tests {
+		tokens, _ := newTestTokenizer().Tokenize(tt.line)
+		if !reflect.DeepEqual(tokens, tt.want) {
+			t.Errorf("Tokenize(%q) = %+v, want %+v", tt.line, tokens, tt.want)
+		}
+	}
+}
+
+func TestSplitReturnsNoWordsOnError(t *testing.T) {
+	words, err := newTestTokenizer().Split(`echo "open`)
+	if err != ErrUnterminatedQuote || words != nil {
+		t.Errorf("Split = %q, %v, want nil, %v", words, err, ErrUnterminatedQuote)
+	}
+}
@@ -0,0 +1,314 @@
+package commands
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+)
+
+//Node : A command of the catalog, the root node is docker itself
+type Node struct {
+	Name        string
+	Description string
+	//Aliases : Other names the command is reachable by under the same parent, e.g. "list" for "ls"
+	Aliases []string
+	//Deprecated : Why the command is deprecated, empty for supported commands
+	Deprecated string
+	Requires   Requirement
+	Flags      []Flag
+	//Args : Positional arguments the command takes, in order
+	Args     []ArgSpec
+	Children []*Node
+
+	parent *Node
+}
+
+//ArgSpec : A positional argument as written in the usage line, e.g. CONTAINER [CONTAINER...]
+type ArgSpec struct {
+	Name     string
+	Type     ValueType
+	Optional bool
+	//Repeated : The argument may be given any number of times
+	Repeated bool
+}
+
+//argumentTypes : Value types of the argument names used by docker usage lines
+var argumentTypes = map[string]ValueType{
+	"CONFIG":    ConfigValue,
+	"CONTAINER": ContainerValue,
+	"DEST_PATH": PathValue,
+	"FILE":      PathValue,
+	"IMAGE":     ImageValue,
+	"NETWORK":   NetworkValue,
+	"PATH":      PathValue,
+	"SECRET":    SecretValue,
+	"SRC_PATH":  PathValue,
+	"VOLUME":    VolumeValue,
+}
+
+//classicCommands : Management commands whose classic top-level name differs from the subcommand
+var classicCommands = map[string]string{
+	"container list":   "ps",
+	"container ls":     "ps",
+	"container ps":     "ps",
+	"container remove": "rm",
+	"image list":       "images",
+	"image ls":         "images",
+	"image remove":     "rmi",
+	"image rm":         "rmi",
+}
+
+var (
+	usageExpression    = regexp.MustCompile(`^Usage:\s+(.*)$`)
+	argumentExpression = regexp.MustCompile(`^[A-Z][A-Z_]*`)
+	deprecatedWord     = regexp.MustCompile(`(?i)\bdeprecated\b`)
+)
+
+//CanonicalCommand : The classic name of a docker container or docker image subcommand, e.g.
+//"ps" for "container ls", every other command path is returned unchanged
+func CanonicalCommand(command string) string {
+	if classic, ok := classicCommands[command]; ok {
+		return classic
+	}
+
+	for _, group := range []string{"container ", "image "} {
+		if strings.HasPrefix(command, group) {
+			return strings.TrimPrefix(command, group)
+		}
+	}
+	return command
+}
+
+//Path : Names of the commands from the top-level command down to this node
+func (n *Node) Path() []string {
+	path := []string{}
+	for node := n; node != nil && node.parent != nil; node = node.parent {
+		path = append([]string{node.Name}, path...)
+	}
+	return path
+}
+
+//Command : The path of the node joined the same way DockerSubSuggestions is keyed
+func (n *Node) Command() string {
+	return strings.Join(n.Path(), " ")
+}
+
+//Child : The subcommand reachable by the given name or one of its aliases
+func (n *Node) Child(name string) *Node {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	for _, child := range n.Children {
+		for _, alias := range child.Aliases {
+			if alias == name {
+				return child
+			}
+		}
+	}
+	return nil
+}
+
+//Resolve : Walks the words down the subcommands and returns the deepest command reached
+//along with the words that follow it
+func (n *Node) Resolve(words []string) (*Node, []string) {
+	node := n
+	for i, word := range words {
+		if strings.HasPrefix(word, "-") {
+			return node, words[i:]
+		}
+
+		child := node.Child(word)
+		if child == nil {
+			return node, words[i:]
+		}
+		node = child
+	}
+	return node, nil
+}
+
+//Flag : Finds a flag of the command by its long or short name
+func (n *Node) Flag(name string) (Flag, bool) {
+	for _, flag := range n.Flags {
+		if flag.Name == name || (flag.Short != "" && flag.Short == name) {
+			return flag, true
+		}
+	}
+	return Flag{}, false
+}
+
+//Arg : The positional argument at the given index, repeated arguments cover every later index
+func (n *Node) Arg(index int) (ArgSpec, bool) {
+	if index < len(n.Args) {
+		return n.Args[index], true
+	}
+	if last := len(n.Args) - 1; last >= 0 && n.Args[last].Repeated {
+		return n.Args[last], true
+	}
+	return ArgSpec{}, false
+}
+
+//SubCommandSuggestions : The subcommands of the node, deprecated ones are marked as such
+func (n *Node) SubCommandSuggestions() []prompt.Suggest {
+	result := []prompt.Suggest{}
+	for _, child := range n.Children {
+		description := child.Description
+		if child.Deprecated != "" && !deprecatedWord.MatchString(description) {
+			description = "(deprecated) " + description
+		}
+		result = append(result, prompt.Suggest{Text: child.Name, Description: description})
+	}
+	return result
+}
+
+//FlagSuggestions : The flags of the node by their long names
+func (n *Node) FlagSuggestions() []prompt.Suggest {
+	result := []prompt.Suggest{}
+	for _, flag := range n.Flags {
+		result = append(result, prompt.Suggest{Text: flag.Name, Description: flag.Description})
+	}
+	return result
+}
+
+//Lookup : The node of the given command path, nil when the catalog does not know it
+func (c *Commands) Lookup(command string) *Node {
+	if c.Root == nil {
+		return nil
+	}
+
+	node, rest := c.Root.Resolve(strings.Fields(command))
+	if len(rest) > 0 || node == c.Root {
+		return nil
+	}
+	return node
+}
+
+//withTree : Builds the command tree out of the flat suggestion maps of the catalog
+func withTree(c Commands) Commands {
+	c.Root = &Node{Name: "docker"}
+	for _, s := range c.DockerSuggestions {
+		c.Root.Children = append(c.Root.Children, c.buildNode(c.Root, s))
+	}
+	return c
+}
+
+func (c *Commands) buildNode(parent *Node, s prompt.Suggest) *Node {
+	node := &Node{Name: s.Text, Description: s.Description, parent: parent}
+	command := node.Command()
+
+	if deprecatedWord.MatchString(s.Description) {
+		node.Deprecated = s.Description
+	}
+	node.Aliases = c.Aliases[command]
+	node.Requires = commandRequirement(command)
+	node.Args = parseUsageArgs(c.Usage[command])
+
+	suggestions, ok := c.DockerSubSuggestions[command]
+	if !ok {
+		suggestions = c.DockerSubSuggestions[CanonicalCommand(command)]
+	}
+	flags, ok := c.Flags[command]
+	if !ok {
+		flags = c.Flags[CanonicalCommand(command)]
+	}
+
+	for _, sub := range suggestions {
+		if !strings.HasPrefix(sub.Text, "-") {
+			node.Children = append(node.Children, c.buildNode(node, sub))
+		}
+	}
+
+	if len(flags) > 0 {
+		node.Flags = append([]Flag{}, flags...)
+	} else {
+		node.Flags = builtinFlags(command, suggestions)
+	}
+
+	for i := range node.Flags {
+		node.Flags[i].Requires = flagRequirement(command, node.Flags[i].Name)
+	}
+	return node
+}
+
+//builtinFlags : The built-in catalog only knows the flag names, their types come from the static tables
+func builtinFlags(command string, suggestions []prompt.Suggest) []Flag {
+	command = CanonicalCommand(command)
+	flags := []Flag{}
+	for _, sub := range suggestions {
+		if !strings.HasPrefix(sub.Text, "-") {
+			continue
+		}
+
+		flag := Flag{Name: sub.Text, Description: sub.Description}
+		if takesValueStatic(command, sub.Text) {
+			flag.Type = string(StringValue)
+		}
+		flags = append(flags, flag)
+	}
+	return withShortFlags(flags, shortFlags[command])
+}
+
+//withShortFlags : Records the short names of the built-in flags, flags missing from the
+//suggestions are added so their short name is still recognized
+func withShortFlags(flags []Flag, shorts map[string]string) []Flag {
+	for _, short := range sortedShorts(shorts) {
+		long := shorts[short]
+		found := false
+		for i := range flags {
+			if flags[i].Name == long {
+				flags[i].Short = short
+				found = true
+			}
+		}
+
+		if !found {
+			flag := Flag{Name: long, Short: short}
+			if !booleanFlags[long] {
+				flag.Type = string(StringValue)
+			}
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+func sortedShorts(shorts map[string]string) []string {
+	keys := []string{}
+	for short := range shorts {
+		keys = append(keys, short)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//parseUsageArgs : Reads the positional arguments of a usage line like
+//"docker container rm [OPTIONS] CONTAINER [CONTAINER...]"
+func parseUsageArgs(usage string) []ArgSpec {
+	args := []ArgSpec{}
+	for _, word := range strings.Fields(usage) {
+		optional := strings.HasPrefix(word, "[")
+		word = strings.Trim(word, "[]")
+		repeated := strings.HasSuffix(word, "...")
+		word = strings.TrimSuffix(word, "...")
+
+		name := argumentExpression.FindString(word)
+		if name == "" || name == "OPTIONS" {
+			continue
+		}
+
+		if last := len(args) - 1; repeated && last >= 0 && args[last].Name == name {
+			args[last].Repeated = true
+			continue
+		}
+
+		valueType, ok := argumentTypes[name]
+		if !ok {
+			valueType = StringValue
+		}
+		args = append(args, ArgSpec{Name: name, Type: valueType, Optional: optional, Repeated: repeated})
+	}
+	return args
+}
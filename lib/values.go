@@ -0,0 +1,296 @@
+package commands
+
+import (
+	"github.com/c-bata/go-prompt"
+)
+
+//ValueType : The kind of value a flag takes
+type ValueType string
+
+const (
+	StringValue      ValueType = "string"
+	EnumValue        ValueType = "enum"
+	DurationValue    ValueType = "duration"
+	BytesValue       ValueType = "bytes"
+	PortValue        ValueType = "port"
+	PathValue        ValueType = "path"
+	ContainerValue   ValueType = "container"
+	ImageValue       ValueType = "image"
+	NetworkValue     ValueType = "network"
+	VolumeValue      ValueType = "volume"
+	VolumeMountValue ValueType = "volume-mount"
+	ConfigValue      ValueType = "config"
+	SecretValue      ValueType = "secret"
+	KeyValueValue    ValueType = "key=value"
+	EnvValue         ValueType = "env"
+	SignalValue      ValueType = "signal"
+	UserValue        ValueType = "user"
+	LogDriverValue   ValueType = "log-driver"
+)
+
+//FlagValue : Describes the value of a flag, Values holds the accepted or typical values
+type FlagValue struct {
+	Type   ValueType
+	Values []prompt.Suggest
+}
+
+var (
+	signalValues = FlagValue{Type: SignalValue, Values: []prompt.Suggest{
+		{Text: "SIGHUP", Description: "Hangup"},
+		{Text: "SIGINT", Description: "Interrupt"},
+		{Text: "SIGQUIT", Description: "Quit"},
+		{Text: "SIGKILL", Description: "Kill, cannot be caught or ignored"},
+		{Text: "SIGUSR1", Description: "User defined signal 1"},
+		{Text: "SIGUSR2", Description: "User defined signal 2"},
+		{Text: "SIGTERM", Description: "Termination"},
+		{Text: "SIGCONT", Description: "Continue if stopped"},
+		{Text: "SIGSTOP", Description: "Stop, cannot be caught or ignored"},
+		{Text: "SIGWINCH", Description: "Window resize"},
+	}}
+	durationValues = FlagValue{Type: DurationValue, Values: []prompt.Suggest{
+		{Text: "10s", Description: "10 seconds"},
+		{Text: "30s", Description: "30 seconds"},
+		{Text: "1m", Description: "1 minute"},
+		{Text: "5m", Description: "5 minutes"},
+		{Text: "1h", Description: "1 hour"},
+	}}
+	bytesValues = FlagValue{Type: BytesValue, Values: []prompt.Suggest{
+		{Text: "64m", Description: "64 megabytes"},
+		{Text: "128m", Description: "128 megabytes"},
+		{Text: "256m", Description: "256 megabytes"},
+		{Text: "512m", Description: "512 megabytes"},
+		{Text: "1g", Description: "1 gigabyte"},
+		{Text: "2g", Description: "2 gigabytes"},
+	}}
+	userValues = FlagValue{Type: UserValue, Values: []prompt.Suggest{
+		{Text: "root", Description: "Run as root"},
+		{Text: "nobody", Description: "Run as nobody"},
+		{Text: "0:0", Description: "uid:gid of root"},
+		{Text: "1000:1000", Description: "uid:gid of the first regular user"},
+	}}
+	secondsValues = FlagValue{Type: StringValue, Values: []prompt.Suggest{
+		{Text: "0", Description: "Do not wait"},
+		{Text: "10", Description: "10 seconds (default)"},
+		{Text: "30", Description: "30 seconds"},
+	}}
+	attachValues = FlagValue{Type: EnumValue, Values: []prompt.Suggest{
+		{Text: "STDIN"},
+		{Text: "STDOUT"},
+		{Text: "STDERR"},
+	}}
+	labelValues     = FlagValue{Type: KeyValueValue}
+	pathValues      = FlagValue{Type: PathValue}
+	envValues       = FlagValue{Type: EnvValue}
+	portValues      = FlagValue{Type: PortValue}
+	imageValues     = FlagValue{Type: ImageValue}
+	containerValues = FlagValue{Type: ContainerValue}
+	networkValues   = FlagValue{Type: NetworkValue}
+	mountValues     = FlagValue{Type: VolumeMountValue}
+	configValues    = FlagValue{Type: ConfigValue}
+	secretValues    = FlagValue{Type: SecretValue}
+	filterValues    = FlagValue{Type: KeyValueValue, Values: []prompt.Suggest{
+		{Text: "status=", Description: "created|restarting|running|removing|paused|exited|dead"},
+		{Text: "name=", Description: "Name of the object"},
+		{Text: "label=", Description: "Label key or key=value"},
+		{Text: "ancestor=", Description: "Created from the image"},
+		{Text: "dangling=true", Description: "Untagged images or unused volumes"},
+		{Text: "before=", Description: "Created before the object"},
+		{Text: "since=", Description: "Created after the object"},
+		{Text: "reference=", Description: "Image reference pattern"},
+	}}
+	updateActionValues = FlagValue{Type: EnumValue, Values: []prompt.Suggest{
+		{Text: "pause"},
+		{Text: "continue"},
+		{Text: "rollback"},
+	}}
+	orderValues = FlagValue{Type: EnumValue, Values: []prompt.Suggest{
+		{Text: "start-first", Description: "Start the new task before stopping the old one"},
+		{Text: "stop-first", Description: "Stop the old task before starting the new one"},
+	}}
+)
+
+//flagValues : Value descriptions of long flags shared by every command
+var flagValues = map[string]FlagValue{
+	"--attach":          attachValues,
+	"--cache-from":      imageValues,
+	"--cap-add":         capabilityValues,
+	"--cap-drop":        capabilityValues,
+	"--cidfile":         pathValues,
+	"--config":          configValues,
+	"--config-add":      configValues,
+	"--config-rm":       configValues,
+	"--container-label": labelValues,
+	"--detach-keys": {Type: EnumValue, Values: []prompt.Suggest{
+		{Text: "ctrl-p,ctrl-q", Description: "Default detach sequence"},
+	}},
+	"--device":        pathValues,
+	"--endpoint-mode": {Type: EnumValue, Values: []prompt.Suggest{{Text: "vip"}, {Text: "dnsrr"}}},
+	"--env":           envValues,
+	"--env-add":       envValues,
+	"--env-file":      pathValues,
+	"--env-rm":        envValues,
+	"--file":          pathValues,
+	"--filter":        filterValues,
+	"--format": {Type: StringValue, Values: []prompt.Suggest{
+		{Text: "json", Description: "Print as JSON"},
+		{Text: "table", Description: "Print as a table"},
+		{Text: "{{json .}}", Description: "Print every object as JSON"},
+		{Text: "{{.ID}}", Description: "Print IDs only"},
+	}},
+	"--health-interval":     durationValues,
+	"--health-start-period": durationValues,
+	"--health-timeout":      durationValues,
+	"--iidfile":             pathValues,
+	"--image":               imageValues,
+	"--input":               pathValues,
+	"--ipc": {Type: EnumValue, Values: []prompt.Suggest{
+		{Text: "none"}, {Text: "private"}, {Text: "shareable"}, {Text: "host"}, {Text: "container:"},
+	}},
+	"--isolation": {Type: EnumValue, Values: []prompt.Suggest{
+		{Text: "default"}, {Text: "process"}, {Text: "hyperv"},
+	}},
+	"--kernel-memory": bytesValues,
+	"--label":         labelValues,
+	"--label-add":     labelValues,
+	"--label-file":    pathValues,
+	"--limit-memory":  bytesValues,
+	"--link":          containerValues,
+	"--log-driver":    {Type: LogDriverValue},
+	"--log-opt": {Type: KeyValueValue, Values: []prompt.Suggest{
+		{Text: "max-size=", Description: "Maximum size of the log before it is rolled"},
+		{Text: "max-file=", Description: "Maximum number of log files"},
+		{Text: "mode=", Description: "blocking|non-blocking"},
+		{Text: "max-buffer-size=", Description: "Buffer size in non-blocking mode"},
+		{Text: "tag=", Description: "Tag added to log messages"},
+		{Text: "labels=", Description: "Labels added to log messages"},
+		{Text: "env=", Description: "Environment variables added to log messages"},
+	}},
+	"--memory":             bytesValues,
+	"--memory-reservation": bytesValues,
+	"--memory-swap":        bytesValues,
+	"--mode":               {Type: EnumValue, Values: []prompt.Suggest{{Text: "replicated"}, {Text: "global"}}},
+	"--mount": {Type: KeyValueValue, Values: []prompt.Suggest{
+		{Text: "type=bind,source=,target=", Description: "Bind mount a host path"},
+		{Text: "type=volume,source=,target=", Description: "Mount a volume"},
+		{Text: "type=tmpfs,target=", Description: "Mount a tmpfs"},
+	}},
+	"--mount-add":    {Type: KeyValueValue},
+	"--net":          networkValues,
+	"--network":      networkValues,
+	"--network-add":  networkValues,
+	"--network-rm":   networkValues,
+	"--orchestrator": {Type: EnumValue, Values: []prompt.Suggest{{Text: "swarm"}, {Text: "kubernetes"}, {Text: "all"}}},
+	"--output":       pathValues,
+	"--pid":          {Type: EnumValue, Values: []prompt.Suggest{{Text: "host"}, {Text: "container:"}}},
+	"--platform": {Type: EnumValue, Values: []prompt.Suggest{
+		{Text: "linux/amd64"}, {Text: "linux/arm64"}, {Text: "linux/arm/v7"}, {Text: "windows/amd64"},
+	}},
+	"--progress":       {Type: EnumValue, Values: []prompt.Suggest{{Text: "auto"}, {Text: "plain"}, {Text: "tty"}}},
+	"--publish":        portValues,
+	"--publish-add":    portValues,
+	"--reserve-memory": bytesValues,
+	"--restart": {Type: EnumValue, Values: []prompt.Suggest{
+		{Text: "no", Description: "Do not automatically restart the container (default)"},
+		{Text: "always", Description: "Always restart the container if it stops"},
+		{Text: "on-failure", Description: "Restart only if the container exits with a non-zero exit status"},
+		{Text: "on-failure:3", Description: "Restart on failure, at most 3 times"},
+		{Text: "unless-stopped", Description: "Always restart unless the container was explicitly stopped"},
+	}},
+	"--restart-condition":       {Type: EnumValue, Values: []prompt.Suggest{{Text: "none"}, {Text: "on-failure"}, {Text: "any"}}},
+	"--restart-delay":           durationValues,
+	"--restart-window":          durationValues,
+	"--rollback-delay":          durationValues,
+	"--rollback-failure-action": {Type: EnumValue, Values: []prompt.Suggest{{Text: "pause"}, {Text: "continue"}}},
+	"--rollback-monitor":        durationValues,
+	"--rollback-order":          orderValues,
+	"--secret":                  secretValues,
+	"--secret-add":              secretValues,
+	"--secret-rm":               secretValues,
+	"--security-opt": {Type: KeyValueValue, Values: []prompt.Suggest{
+		{Text: "no-new-privileges", Description: "Disable container processes from gaining new privileges"},
+		{Text: "seccomp=unconfined", Description: "Turn off seccomp confinement"},
+		{Text: "apparmor=unconfined", Description: "Turn off apparmor confinement"},
+		{Text: "label=disable", Description: "Turn off label confinement"},
+	}},
+	"--shm-size":          bytesValues,
+	"--signal":            signalValues,
+	"--since":             durationValues,
+	"--stop-grace-period": durationValues,
+	"--stop-signal":       signalValues,
+	"--sysctl":            labelValues,
+	"--tmpfs":             pathValues,
+	"--type": {Type: EnumValue, Values: []prompt.Suggest{
+		{Text: "container"}, {Text: "image"}, {Text: "network"}, {Text: "node"}, {Text: "plugin"},
+		{Text: "secret"}, {Text: "service"}, {Text: "task"}, {Text: "volume"}, {Text: "config"},
+	}},
+	"--ulimit": {Type: KeyValueValue, Values: []prompt.Suggest{
+		{Text: "nofile=", Description: "Number of open files (soft:hard)"},
+		{Text: "nproc=", Description: "Number of processes"},
+		{Text: "core=", Description: "Core file size"},
+		{Text: "memlock=", Description: "Locked-in-memory address space"},
+		{Text: "stack=", Description: "Stack size"},
+	}},
+	"--until":                 durationValues,
+	"--update-delay":          durationValues,
+	"--update-failure-action": updateActionValues,
+	"--update-monitor":        durationValues,
+	"--update-order":          orderValues,
+	"--user":                  userValues,
+	"--volume":                mountValues,
+	"--volumes-from":          containerValues,
+}
+
+//commandFlagValues : Value descriptions of long flags that differ from flagValues for one command
+var commandFlagValues = map[string]map[string]FlagValue{
+	"restart": {"--time": secondsValues},
+	"stop":    {"--time": secondsValues},
+}
+
+var capabilityValues = FlagValue{Type: EnumValue, Values: []prompt.Suggest{
+	{Text: "ALL", Description: "All capabilities"},
+	{Text: "AUDIT_WRITE", Description: "Write records to kernel auditing log"},
+	{Text: "CHOWN", Description: "Make arbitrary changes to file UIDs and GIDs"},
+	{Text: "DAC_OVERRIDE", Description: "Bypass file read, write, and execute permission checks"},
+	{Text: "IPC_LOCK", Description: "Lock memory"},
+	{Text: "NET_ADMIN", Description: "Perform various network-related operations"},
+	{Text: "NET_BIND_SERVICE", Description: "Bind a socket to privileged ports"},
+	{Text: "NET_RAW", Description: "Use RAW and PACKET sockets"},
+	{Text: "SETGID", Description: "Make arbitrary manipulations of process GIDs"},
+	{Text: "SETUID", Description: "Make arbitrary manipulations of process UIDs"},
+	{Text: "SYS_ADMIN", Description: "Perform a range of system administration operations"},
+	{Text: "SYS_PTRACE", Description: "Trace arbitrary processes using ptrace"},
+	{Text: "SYS_TIME", Description: "Set the system clock"},
+}}
+
+//GetFlagValue : Describes the value the flag of the given command takes
+func (c *Commands) GetFlagValue(command, flag string) (FlagValue, bool) {
+	flag = c.LongFlag(command, flag)
+	if value, ok := commandFlagValues[CanonicalCommand(command)][flag]; ok {
+		return value, true
+	}
+	if !c.TakesValue(command, flag) {
+		return FlagValue{}, false
+	}
+
+	if value, ok := flagValues[flag]; ok {
+		return value, true
+	}
+
+	if f, ok := c.lookupFlag(command, flag); ok {
+		return helpTypeValues(f.Type), true
+	}
+	return FlagValue{}, false
+}
+
+//helpTypeValues : Maps the value types printed by docker --help to catalog value types
+func helpTypeValues(helpType string) FlagValue {
+	switch helpType {
+	case "duration":
+		return durationValues
+	case "bytes":
+		return bytesValues
+	case "map":
+		return labelValues
+	}
+	return FlagValue{Type: StringValue}
+}
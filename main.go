@@ -3,13 +3,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
-	"reflect"
-	"regexp"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -21,23 +21,48 @@ import (
 	"github.com/c-bata/go-prompt"
 	"github.com/hashicorp/go-retryablehttp"
 	commands "github.com/mstrYoda/docker-shell/lib"
-	"github.com/patrickmn/go-cache"
 )
 
 var dockerClient *docker.Client
-var shellCommands commands.Commands = commands.New()
+var shellCommands = newCatalog()
 
 //DockerHubResult : Wrap DockerHub API call
 type DockerHubResult struct {
-	PageCount        *int                    `json:"num_pages,omitempty"`
-	ResultCount      *int                    `json:"num_results,omitempty"`
-	ItemCountPerPage *int                    `json:"page_size,omitempty"`
-	CurrentPage      *int                    `json:"page,omitempty"`
-	Query            *string                 `json:"query,omitempty"`
-	Items            []registry.SearchResult `json:"results,omitempty"`
+	PageCount        *int            `json:"num_pages,omitempty"`
+	ResultCount      *int            `json:"num_results,omitempty"`
+	ItemCountPerPage *int            `json:"page_size,omitempty"`
+	CurrentPage      *int            `json:"page,omitempty"`
+	Query            *string         `json:"query,omitempty"`
+	Items            []hubRepository `json:"results,omitempty"`
 }
 
-func imageFromHubAPI(count int) []registry.SearchResult {
+//hubRepository : A repository as the DockerHub API lists it
+type hubRepository struct {
+	registry.SearchResult
+	Namespace string `json:"namespace"`
+	IsPrivate bool   `json:"is_private"`
+	PullCount int64  `json:"pull_count"`
+}
+
+//DockerHubSearch : Wrap DockerHub search API call
+type DockerHubSearch struct {
+	Count   int               `json:"count"`
+	Next    string            `json:"next"`
+	Results []hubSearchResult `json:"results"`
+}
+
+//hubSearchResult : A repository found by a search, pull counts are only known to the DockerHub API
+type hubSearchResult struct {
+	Name        string `json:"repo_name"`
+	Description string `json:"short_description"`
+	StarCount   int    `json:"star_count"`
+	PullCount   int64  `json:"pull_count"`
+	IsOfficial  bool   `json:"is_official"`
+	IsAutomated bool   `json:"is_automated"`
+	IsPrivate   bool   `json:"-"`
+}
+
+func hubClient() *retryablehttp.Client {
 	client := retryablehttp.NewClient()
 	client.HTTPClient = &http.Client{
 		Timeout: 1 * time.Second,
@@ -46,34 +71,92 @@ func imageFromHubAPI(count int) []registry.SearchResult {
 	client.RetryWaitMax = client.HTTPClient.Timeout
 	client.RetryMax = 3
 	client.Logger = nil
-	url := url.URL{
-		Scheme:   "https",
-		Host:     "registry.hub.docker.com",
-		Path:     "/v2/repositories/library",
-		RawQuery: "page=1&page_size=" + strconv.Itoa(count),
+	return client
+}
+
+//hubGet : Decodes the answer of the DockerHub API to a GET of the url, with the token of the user when logged in
+func hubGet(ctx context.Context, apiURL url.URL, token string, result interface{}) error {
+	request, err := retryablehttp.NewRequest(http.MethodGet, apiURL.String(), nil)
+	if err != nil {
+		return err
 	}
-	apiURL := url.String()
-	response, err := client.Get(apiURL)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := hubClient().Do(request.WithContext(ctx))
 	if err != nil {
-		return nil
+		return err
 	}
 
 	defer response.Body.Close()
+	if response.StatusCode == http.StatusUnauthorized && token != "" {
+		hubSession.Expire()
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("docker hub responded %s", response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
 
-	decoder := json.NewDecoder(response.Body)
+//imageFromHubAPI : Repositories of the namespace, private ones are only listed with the token of their owner
+func imageFromHubAPI(ctx context.Context, namespace, token string, count int) []hubSearchResult {
+	apiURL := url.URL{
+		Scheme:   "https",
+		Host:     "registry.hub.docker.com",
+		Path:     "/v2/repositories/" + namespace + "/",
+		RawQuery: "page=1&page_size=" + strconv.Itoa(count),
+	}
 	searchResult := &DockerHubResult{}
-	decoder.Decode(searchResult)
-	if searchResult.Items == nil || len(searchResult.Items) <= 0 {
+	if err := hubGet(ctx, apiURL, token, searchResult); err != nil || len(searchResult.Items) <= 0 {
 		return nil
 	}
 
-	return searchResult.Items
+	results := []hubSearchResult{}
+	for _, item := range searchResult.Items {
+		name := item.Name
+		if item.Namespace != "" && item.Namespace != "library" {
+			name = item.Namespace + "/" + item.Name
+		}
+		results = append(results, hubSearchResult{
+			Name:        name,
+			Description: item.Description,
+			StarCount:   item.StarCount,
+			PullCount:   item.PullCount,
+			IsOfficial:  item.Namespace == "library",
+			IsPrivate:   item.IsPrivate,
+		})
+	}
+	return results
 }
 
-func imageFromContext(imageName string, count int) []registry.SearchResult {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+//imageFromHubSearch : One page of the DockerHub search for the query across every namespace
+//and whether the search has further pages
+func imageFromHubSearch(ctx context.Context, query string, page, count int) ([]hubSearchResult, bool, error) {
+	values := url.Values{}
+	values.Set("query", query)
+	values.Set("page", strconv.Itoa(page))
+	values.Set("page_size", strconv.Itoa(count))
+	apiURL := url.URL{
+		Scheme:   "https",
+		Host:     "hub.docker.com",
+		Path:     "/v2/search/repositories/",
+		RawQuery: values.Encode(),
+	}
+
+	token, _ := hubSession.Token(ctx)
+	searchResult := &DockerHubSearch{}
+	if err := hubGet(ctx, apiURL, token, searchResult); err != nil {
+		return nil, false, err
+	}
+	return searchResult.Results, searchResult.Next != "", nil
+}
+
+//imageFromContext : Searches through the daemon, used when the DockerHub API can not be reached directly
+func imageFromContext(ctx context.Context, imageName string, count int) []hubSearchResult {
+	auth := registryAuth(ctx)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
-	ctxResponse, err := dockerClient.ImageSearch(ctx, imageName, types.ImageSearchOptions{Limit: count})
+	ctxResponse, err := dockerClient.ImageSearch(ctx, imageName, types.ImageSearchOptions{RegistryAuth: auth, Limit: count})
 	if err != nil {
 		return nil
 	}
@@ -82,153 +165,207 @@ func imageFromContext(imageName string, count int) []registry.SearchResult {
 		return nil
 	}
 
-	return ctxResponse
+	results := []hubSearchResult{}
+	for _, s := range ctxResponse {
+		results = append(results, hubSearchResult{
+			Name:        s.Name,
+			Description: s.Description,
+			StarCount:   s.StarCount,
+			IsOfficial:  s.IsOfficial,
+			IsAutomated: s.IsAutomated,
+		})
+	}
+	return results
 }
 
-func imageFetchCompleter(imageName string, count int) []prompt.Suggest {
-	searchResult := []registry.SearchResult{}
+//imageFetchCompleter : One page of images for the word, the repositories of the logged in user
+//and the official images when nothing was typed yet. A search without results is an empty page,
+//nil means it failed and is tried again later
+func imageFetchCompleter(ctx context.Context, imageName string, page, count int) []prompt.Suggest {
+	searchResult := []hubSearchResult{}
 	if imageName != "" {
-		searchResult = imageFromContext(imageName, 10)
+		results, more, err := imageFromHubSearch(ctx, imageName, page, count)
+		if err != nil && page == 1 {
+			results, more = imageFromContext(ctx, imageName, count), false
+		}
+		if err != nil && len(results) <= 0 {
+			return nil
+		}
+		searchResult = results
+		if !more {
+			hubSearches.last(imageName, page)
+		}
 	} else {
-		searchResult = imageFromHubAPI(10)
+		token, user := hubSession.Token(ctx)
+		if token != "" && user != "" {
+			searchResult = imageFromHubAPI(ctx, user, token, count)
+		}
+		searchResult = append(searchResult, imageFromHubAPI(ctx, "library", token, count)...)
 	}
 
-	if searchResult == nil || len(searchResult) <= 0 {
+	if len(searchResult) <= 0 && imageName == "" {
 		return nil
 	}
 
 	suggestions := []prompt.Suggest{}
 	for _, s := range searchResult {
-		description := "Not Official"
-		if s.IsOfficial {
-			description = "Official"
-		}
-		suggestions = append(suggestions, prompt.Suggest{Text: s.Name, Description: "(" + description + ") " + s.Description})
+		suggestions = append(suggestions, prompt.Suggest{Text: s.Name, Description: searchDescription(s)})
 	}
 	return suggestions
 }
 
-var commandExpression = regexp.MustCompile(`(?P<command>exec|stop|start|run|service create|service inspect|service logs|service ls|service ps|service rollback|service scale|service update|service|pull|attach|build|commit|cp|create|events|export|history|images|import|info|inspect|kill|load|login|logs|ps|push|restart|rm|rmi|save|search|stack|stats|update|version)\s{1}`)
+//searchDescription : Like (Official) 19k stars, 1.2B pulls | Official build of Nginx.
+func searchDescription(s hubSearchResult) string {
+	labels := []string{}
+	switch {
+	case s.IsOfficial:
+		labels = append(labels, "Official")
+	case s.IsPrivate:
+		labels = append(labels, "Private")
+	default:
+		labels = append(labels, "Not Official")
+	}
+	if s.IsAutomated {
+		labels = append(labels, "Automated")
+	}
 
-func getRegexGroups(text string) map[string]string {
-	if !commandExpression.Match([]byte(text)) {
-		return nil
+	counts := []string{humanCount(int64(s.StarCount)) + " stars"}
+	if s.PullCount > 0 {
+		counts = append(counts, humanCount(s.PullCount)+" pulls")
 	}
+	return "(" + strings.Join(labels, ", ") + ") " + strings.Join(counts, ", ") + " | " + s.Description
+}
 
-	match := commandExpression.FindStringSubmatch(text)
-	result := make(map[string]string)
-	for i, name := range commandExpression.SubexpNames() {
-		if i != 0 && name != "" {
-			result[name] = match[i]
-		}
+//background : Cancelled on exit to stop every background worker
+var background, stopBackground = context.WithCancel(context.Background())
+
+var memoryCache = newSuggestionStore(background, 5*time.Minute, requestRedraw)
+
+func imageCacheKey(word string, page int) string {
+	if word == "" {
+		return "all"
 	}
-	return result
+	return fmt.Sprintf("%s%s:%d", searchPrefix, word, page)
 }
 
-var memoryCache = cache.New(5*time.Minute, 10*time.Minute)
+func imageLoader(word string, page int) suggestionLoader {
+	return func(ctx context.Context) []prompt.Suggest {
+		return imageFetchCompleter(ctx, word, page, searchPageSize)
+	}
+}
 
 func getFromCache(word string) []prompt.Suggest {
-	cacheKey := "all"
-	if word != "" {
-		cacheKey = fmt.Sprintf("completer:%s", word)
-	}
-	completer, found := memoryCache.Get(cacheKey)
-	if !found {
-		completer = imageFetchCompleter(word, 10)
-		if completer.([]prompt.Suggest) == nil {
-			return []prompt.Suggest{}
-		}
-		memoryCache.Set(cacheKey, completer, cache.DefaultExpiration)
+	return hubSearches.suggestions(word)
+}
+
+//suggestionCachePath : The cache file DockerHub and registry suggestions are kept in between sessions
+func suggestionCachePath() string {
+	return cachePath("suggestions.json")
+}
+
+//cachePath : A file of the cache directory of docker-shell, empty when there is none
+func cachePath(name string) string {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		dir, _ = os.UserCacheDir()
 	}
-	return completer.([]prompt.Suggest)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "docker-shell", name)
 }
 
 func completer(d prompt.Document) []prompt.Suggest {
 	word := d.GetWordBeforeCursor()
-
-	group := getRegexGroups(d.Text)
-	if group != nil {
-		command := group["command"]
-
-		if command == "exec" || command == "stop" || command == "port" {
-			return containerListCompleter(false)
+	ctx := catalog().Parse(lineArgs(d))
+	command := ctx.Command()
+
+	switch ctx.Expect {
+	case commands.ExpectCommand:
+		return prompt.FilterHasPrefix(catalog().GetDockerSuggestions(), word, true)
+	case commands.ExpectSubCommand:
+		return prompt.FilterHasPrefix(catalog().GetSubCommandSuggestions(command), word, true)
+	case commands.ExpectFlag:
+		if ctx.Canonical() == "run" && catalog().LongFlag(command, word) == "--publish" {
+			return portMappingSuggestion()
 		}
 
-		if command == "start" {
-			return containerListCompleter(true)
+		if len(word) > 1 && !strings.HasPrefix(word, "--") {
+			return catalog().RemainingFlags(ctx, catalog().GetShortFlagSuggestions(command, word))
 		}
+		return prompt.FilterHasPrefix(catalog().RemainingFlags(ctx, catalog().GetFlagSuggestions(command)), word, true)
+	case commands.ExpectFlagValue:
+		return flagValueCompleter(ctx, word)
+	case commands.ExpectArgument:
+		return argumentCompleter(ctx, word)
+	}
 
-		if command == "run" {
-			if word == "-p" {
-				if len(portMappingSuggestions) > 0 {
-					return portMappingSuggestions
-				}
-
-				return portMappingSuggestion()
-			}
+	return []prompt.Suggest{}
+}
 
-			if len(suggestedImages) > 0 {
-				return suggestedImages
-			}
+func argumentCompleter(ctx commands.Context, word string) []prompt.Suggest {
+	if suggestions, ok := imageArgumentCompleter(ctx, word); ok {
+		return suggestions
+	}
+	if suggestions, ok := containerArgumentCompleter(ctx, word); ok {
+		return suggestions
+	}
+	if suggestions, ok := resourceArgumentCompleter(ctx, word); ok {
+		return suggestions
+	}
 
-			return imagesSuggestion()
+	switch ctx.Canonical() {
+	case "exec":
+		if ctx.ArgIndex() == 1 {
+			return prompt.FilterHasPrefix(containerCommandsCompleter(ctx.Args[0]), word, true)
 		}
-
-		if command == "pull" {
-			if strings.Index(word, ":") != -1 || strings.Index(word, "@") != -1 {
-				return []prompt.Suggest{}
-			}
-
-			if word == "" || len(word) > 2 {
-				if len(strings.Split(d.Text, " ")) > 2 {
-					return []prompt.Suggest{}
-				}
-				return getFromCache(word)
-			}
-
-			return []prompt.Suggest{}
+	case "cp":
+		if ctx.ArgIndex() < 2 {
+			return copyPathCompleter(word)
 		}
-		if val, ok := shellCommands.IsDockerSubCommand(command); ok {
-			return prompt.FilterHasPrefix(val, word, true)
+	case "tag":
+		if ctx.ArgIndex() == 1 {
+			return prompt.FilterHasPrefix(repositoryCompleter(), word, true)
+		}
+	case "pull":
+		if ctx.ArgIndex() == 0 {
+			return pullCompleter(word)
 		}
 	}
 
-	return prompt.FilterHasPrefix(shellCommands.GetDockerSuggestions(), word, true)
+	if arg, ok := ctx.Arg(); ok && arg.Type != commands.StringValue {
+		return valueTypeCompleter(arg.Type, word)
+	}
+	return []prompt.Suggest{}
 }
 
-func containerListCompleter(all bool) []prompt.Suggest {
-	suggestions := []prompt.Suggest{}
-	ctx := context.Background()
-	cList, _ := dockerClient.ContainerList(ctx, types.ContainerListOptions{All: all})
+//lineArgs : Tokenizes the text before the cursor, the last element is the word being typed
+func lineArgs(d prompt.Document) []string {
+	text := d.TextBeforeCursor()
+	tokens, _ := newTokenizer().Tokenize(text)
 
-	for _, container := range cList {
-		suggestions = append(suggestions, prompt.Suggest{Text: container.ID, Description: container.Image})
+	args := []string{}
+	for _, token := range tokens {
+		args = append(args, token.Value)
 	}
-
-	return suggestions
+	if len(tokens) == 0 || tokens[len(tokens)-1].End < len(text) {
+		args = append(args, "")
+	}
+	return args
 }
 
-var portMappingSuggestions []prompt.Suggest
-
 func portMappingSuggestion() []prompt.Suggest {
-	images, _ := dockerClient.ImageList(context.Background(), types.ImageListOptions{All: true})
 	suggestions := []prompt.Suggest{}
 
-	for _, image := range images {
-		inspection, _, _ := dockerClient.ImageInspectWithRaw(context.Background(), image.ID)
-
-		exposedPortKeys := reflect.ValueOf(inspection.Config.ExposedPorts).MapKeys()
-
-		for _, exposedPort := range exposedPortKeys {
-			portAndType := strings.Split(exposedPort.String(), "/")
+	for _, image := range model.imageList() {
+		for _, exposedPort := range image.ports {
+			portAndType := strings.Split(exposedPort, "/")
 			port := portAndType[0]
 			portType := portAndType[1]
-			suggestions = append(suggestions, prompt.Suggest{Text: fmt.Sprintf("-p %s:%s/%s", port, port, portType), Description: getDescription(inspection)})
+			suggestions = append(suggestions, prompt.Suggest{Text: fmt.Sprintf("-p %s:%s/%s", port, port, portType), Description: image.digest})
 		}
 	}
 
-	portMappingSuggestions = suggestions
-
 	return suggestions
 }
 
@@ -240,63 +377,63 @@ func getDescription(inspection types.ImageInspect) string {
 	return desc
 }
 
-var suggestedImages []prompt.Suggest
-
-func imagesSuggestion() []prompt.Suggest {
-	images, _ := dockerClient.ImageList(context.Background(), types.ImageListOptions{All: true})
-	suggestions := []prompt.Suggest{}
+func main() {
+	offline := flag.Bool("offline", false, "complete DockerHub and registry images only from the cache, without network calls")
+	flag.Parse()
 
-	for _, image := range images {
-		ins, _, _ := dockerClient.ImageInspectWithRaw(context.Background(), image.ID)
-		suggestions = append(suggestions, prompt.Suggest{Text: image.ID[7:19], Description: getDescription(ins)})
+	if err := memoryCache.Persist(suggestionCachePath()); err != nil {
+		fmt.Fprintln(os.Stderr, "Ignoring the suggestion cache:", err)
+	}
+	if *offline {
+		memoryCache.Offline()
 	}
 
-	suggestedImages = suggestions
-
-	return suggestions
-}
-
-func main() {
 	dockerClient, _ = docker.NewEnvClient()
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	if _, err := dockerClient.Ping(ctx); err != nil {
+	ping, err := dockerClient.Ping(ctx)
+	if err != nil {
 		fmt.Println("Couldn't check docker status please make sure docker is running.")
 		fmt.Println(err)
 		return
 	}
-	go getFromCache("")
+	version, _ := dockerClient.ServerVersion(ctx)
+	connectCatalog(ping, version)
+	go connectRegistries()
+	memoryCache.Refresh(imageCacheKey("", 1), imageLoader("", 1))
+	go refreshCatalog(*offline)
+	go model.watch(background)
+	input := newRedrawParser()
 	for {
-		dockerCommand := prompt.Input(">>> docker ",
-			completer,
+		selection.reset()
+		dockerCommand := prompt.Input(promptPrefix(),
+			trackSelection(completer),
+			prompt.OptionParser(input),
 			prompt.OptionTitle("docker prompt"),
 			prompt.OptionSelectedDescriptionTextColor(prompt.Turquoise),
 			prompt.OptionInputTextColor(prompt.Fuchsia),
 			prompt.OptionPrefixBackgroundColor(prompt.Cyan))
 
-		splittedDockerCommands := strings.Split(dockerCommand, " ")
-		if splittedDockerCommands[0] == "exit" {
-			os.Exit(0)
+		splittedDockerCommands, err := newTokenizer().Split(dockerCommand)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			lastExitCode = 2
+			continue
 		}
-
-		var ps *exec.Cmd
-
-		if splittedDockerCommands[0] == "clear" {
-			ps = exec.Command("clear")
-		} else {
-			ps = exec.Command("docker", splittedDockerCommands...)
+		if len(splittedDockerCommands) == 0 {
+			continue
 		}
 
-		res, err := ps.Output()
-
-		if err != nil {
-			fmt.Println(err)
+		if splittedDockerCommands[0] == "exit" {
+			stopBackground()
+			os.Exit(0)
 		}
 
-		fmt.Println(string(res))
-
-		portMappingSuggestions = []prompt.Suggest{}
-		suggestedImages = []prompt.Suggest{}
+		err = execute(splittedDockerCommands)
+		lastExitCode = exitCode(err)
+		if _, ok := err.(*exec.ExitError); err != nil && !ok {
+			fmt.Fprintln(os.Stderr, err)
+		}
 	}
 }
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"docker.io/go-docker/api/types"
+	"docker.io/go-docker/api/types/events"
+	"docker.io/go-docker/api/types/filters"
+)
+
+//resyncDelay : How long to wait before subscribing again once the events stream broke
+const resyncDelay = 5 * time.Second
+
+//imageEntry : A local image with the details the completers show, inspected once when it appears
+type imageEntry struct {
+	summary      types.ImageSummary
+	digest       string
+	architecture string
+	//ports : Exposed ports like 80/tcp
+	ports []string
+}
+
+//dockerModel : In-memory copy of the daemon's containers, images, networks and volumes.
+//It is loaded once and then kept up to date by the events stream, so completers never
+//wait for the daemon
+type dockerModel struct {
+	lock       sync.RWMutex
+	containers map[string]types.Container
+	images     map[string]imageEntry
+	networks   map[string]types.NetworkResource
+	volumes    map[string]types.Volume
+}
+
+var model = newDockerModel()
+
+func newDockerModel() *dockerModel {
+	return &dockerModel{
+		containers: map[string]types.Container{},
+		images:     map[string]imageEntry{},
+		networks:   map[string]types.NetworkResource{},
+		volumes:    map[string]types.Volume{},
+	}
+}
+
+//watch : Loads the model and applies the events of the daemon to it until ctx is done,
+//the model is loaded again whenever the events stream breaks since events may have been missed
+func (m *dockerModel) watch(ctx context.Context) {
+	for ctx.Err() == nil {
+		since := time.Now()
+		m.load()
+		m.follow(ctx, since)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(resyncDelay):
+		}
+	}
+}
+
+func (m *dockerModel) follow(ctx context.Context, since time.Time) {
+	options := types.EventsOptions{Since: strconv.FormatInt(since.Unix(), 10), Filters: filters.NewArgs()}
+	for _, kind := range []string{"container", "image", "network", "volume"} {
+		options.Filters.Add("type", kind)
+	}
+
+	messages, errs := dockerClient.Events(ctx, options)
+	for {
+		select {
+		case message := <-messages:
+			m.apply(message)
+		case <-errs:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+//load : Replaces the whole model with what the daemon currently has
+func (m *dockerModel) load() {
+	m.loadContainers(filters.NewArgs())
+	m.loadImages()
+	m.loadNetworks(filters.NewArgs())
+	m.loadVolumes(filters.NewArgs())
+}
+
+//containerEvents : Container actions changing what docker ps shows, destroy is handled apart
+var containerEvents = map[string]bool{
+	"create":  true,
+	"die":     true,
+	"kill":    true,
+	"oom":     true,
+	"pause":   true,
+	"rename":  true,
+	"restart": true,
+	"start":   true,
+	"stop":    true,
+	"unpause": true,
+	"update":  true,
+}
+
+//imageEvents : Image actions changing the local images or their tags
+var imageEvents = map[string]bool{
+	"delete": true,
+	"import": true,
+	"load":   true,
+	"pull":   true,
+	"tag":    true,
+	"untag":  true,
+}
+
+//apply : Updates the part of the model the event is about
+func (m *dockerModel) apply(message events.Message) {
+	id := message.Actor.ID
+	switch message.Type {
+	case "container":
+		switch {
+		case message.Action == "destroy":
+			m.remove(func() { delete(m.containers, id) })
+		case containerEvents[message.Action] || strings.HasPrefix(message.Action, "health_status"):
+			m.loadContainers(filters.NewArgs(filters.Arg("id", id)))
+		}
+	case "image":
+		if imageEvents[message.Action] {
+			m.loadImages()
+		}
+	case "network":
+		switch message.Action {
+		case "destroy":
+			m.remove(func() { delete(m.networks, id) })
+		case "create":
+			m.loadNetworks(filters.NewArgs(filters.Arg("id", id)))
+		}
+	case "volume":
+		switch message.Action {
+		case "destroy":
+			m.remove(func() { delete(m.volumes, id) })
+		case "create":
+			m.loadVolumes(filters.NewArgs(filters.Arg("name", id)))
+		}
+	}
+}
+
+func (m *dockerModel) remove(remove func()) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	remove()
+}
+
+//loadContainers : Reloads the containers matching the filter, every container when it is empty
+func (m *dockerModel) loadContainers(filter filters.Args) {
+	ctx, cancel := resourceContext()
+	defer cancel()
+
+	containers, err := dockerClient.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if filter.Len() == 0 {
+		m.containers = map[string]types.Container{}
+	}
+	for _, container := range containers {
+		m.containers[container.ID] = container
+	}
+}
+
+//loadImages : Reloads the image list, only images not seen before are inspected, a few at a time
+func (m *dockerModel) loadImages() {
+	ctx, cancel := resourceContext()
+	defer cancel()
+
+	summaries, err := dockerClient.ImageList(ctx, types.ImageListOptions{All: true})
+	if err != nil {
+		return
+	}
+
+	m.lock.RLock()
+	known := m.images
+	m.lock.RUnlock()
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, refreshWorkers)
+
+	images := map[string]imageEntry{}
+	for _, summary := range summaries {
+		if entry, ok := known[summary.ID]; ok {
+			entry.summary = summary
+			images[summary.ID] = entry
+			continue
+		}
+
+		wg.Add(1)
+		go func(summary types.ImageSummary) {
+			defer wg.Done()
+
+			workers <- struct{}{}
+			entry := inspectImage(summary.ID)
+			<-workers
+
+			entry.summary = summary
+			lock.Lock()
+			images[summary.ID] = entry
+			lock.Unlock()
+		}(summary)
+	}
+	wg.Wait()
+
+	m.lock.Lock()
+	m.images = images
+	m.lock.Unlock()
+}
+
+func inspectImage(id string) imageEntry {
+	ctx, cancel := resourceContext()
+	defer cancel()
+
+	inspection, _, err := dockerClient.ImageInspectWithRaw(ctx, id)
+	if err != nil {
+		return imageEntry{}
+	}
+
+	entry := imageEntry{digest: getDescription(inspection), architecture: inspection.Architecture}
+	if inspection.Config != nil {
+		for _, port := range reflect.ValueOf(inspection.Config.ExposedPorts).MapKeys() {
+			entry.ports = append(entry.ports, port.String())
+		}
+		sort.Strings(entry.ports)
+	}
+	return entry
+}
+
+//loadNetworks : Reloads the networks matching the filter, every network when it is empty
+func (m *dockerModel) loadNetworks(filter filters.Args) {
+	ctx, cancel := resourceContext()
+	defer cancel()
+
+	networks, err := dockerClient.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+	if err != nil {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if filter.Len() == 0 {
+		m.networks = map[string]types.NetworkResource{}
+	}
+	for _, network := range networks {
+		m.networks[network.ID] = network
+	}
+}
+
+//loadVolumes : Reloads the volumes matching the filter, every volume when it is empty
+func (m *dockerModel) loadVolumes(filter filters.Args) {
+	ctx, cancel := resourceContext()
+	defer cancel()
+
+	response, err := dockerClient.VolumeList(ctx, filter)
+	if err != nil {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if filter.Len() == 0 {
+		m.volumes = map[string]types.Volume{}
+	}
+	for _, volume := range response.Volumes {
+		if volume != nil {
+			m.volumes[volume.Name] = *volume
+		}
+	}
+}
+
+//containerList : The containers in one of the given states, every container when no state is given
+func (m *dockerModel) containerList(states []string) []types.Container {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	accepted := map[string]bool{}
+	for _, state := range states {
+		accepted[state] = true
+	}
+
+	containers := []types.Container{}
+	for _, container := range m.containers {
+		if len(states) == 0 || accepted[container.State] {
+			containers = append(containers, container)
+		}
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Created > containers[j].Created })
+	return containers
+}
+
+//imageList : The local images, the most recently created first
+func (m *dockerModel) imageList() []imageEntry {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	images := []imageEntry{}
+	for _, image := range m.images {
+		images = append(images, image)
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].summary.Created > images[j].summary.Created })
+	return images
+}
+
+func (m *dockerModel) networkList() []types.NetworkResource {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	networks := []types.NetworkResource{}
+	for _, network := range m.networks {
+		networks = append(networks, network)
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+	return networks
+}
+
+func (m *dockerModel) volumeList() []types.Volume {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	volumes := []types.Volume{}
+	for _, volume := range m.volumes {
+		volumes = append(volumes, volume)
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	return volumes
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/mstrYoda/docker-shell/lib/registry"
+)
+
+//pullCompleter : Completes the image of docker pull. Registry hosts and repositories from the search
+//come first, a word starting with a registry host lists the catalog of that registry,
+//repository:tag the tags of the repository and repository@digest its manifest list
+func pullCompleter(word string) []prompt.Suggest {
+	if i := strings.Index(word, "@"); i > 0 {
+		return digestCompleter(word[:i], word)
+	}
+
+	hosts := []prompt.Suggest{}
+	if !strings.Contains(word, "/") {
+		hosts = registries.hostSuggestions(word)
+	}
+	if strings.Contains(word, ":") && len(hosts) > 0 {
+		return hosts
+	}
+	if i := strings.LastIndex(word, ":"); i > strings.LastIndex(word, "/") {
+		return tagCompleter(word[:i], word)
+	}
+
+	if host, _ := registry.SplitHost(word); host != "" {
+		return catalogCompleter(host, word)
+	}
+	if word == "" || len(word) > 2 {
+		return append(hosts, getFromCache(word)...)
+	}
+	return hosts
+}
+
+//catalogCompleter : Suggests host/repository for every repository in the catalog of the registry
+func catalogCompleter(host, word string) []prompt.Suggest {
+	repositories := memoryCache.Peek("catalog:"+host, func(ctx context.Context) []prompt.Suggest {
+		repositories, err := registries.client(ctx, host).Catalog(ctx)
+		if err != nil && len(repositories) == 0 {
+			return nil
+		}
+
+		suggestions := []prompt.Suggest{}
+		for _, repository := range repositories {
+			suggestions = append(suggestions, prompt.Suggest{Text: repository, Description: "Repository on " + host})
+		}
+		return suggestions
+	})
+
+	return prompt.FilterHasPrefix(withPrefix(repositories, host+"/"), word, true)
+}
+
+//tagCompleter : Suggests name:tag for every tag of the repository, latest and the newest versions first
+func tagCompleter(name, word string) []prompt.Suggest {
+	tags := memoryCache.Peek("tags:"+imageKey(name), func(ctx context.Context) []prompt.Suggest {
+		clients, repository := registries.lookup(ctx, name)
+		for _, client := range clients {
+			tags, err := client.Tags(ctx, repository)
+			if err != nil && len(tags) == 0 {
+				continue
+			}
+
+			registry.SortTags(tags)
+			suggestions := []prompt.Suggest{}
+			for _, tag := range tags {
+				suggestions = append(suggestions, prompt.Suggest{Text: tag, Description: "Tag of " + repository})
+			}
+			return suggestions
+		}
+		return nil
+	})
+
+	return prompt.FilterHasPrefix(withPrefix(tags, name+":"), word, true)
+}
+
+//digestCompleter : Suggests name@digest for the manifest list of the tag in the name, latest when it
+//has none, and for each of its platform manifests
+func digestCompleter(name, word string) []prompt.Suggest {
+	repository, reference := name, "latest"
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		repository, reference = name[:i], name[i+1:]
+	}
+
+	digests := memoryCache.Peek("digests:"+imageKey(repository)+":"+reference, func(ctx context.Context) []prompt.Suggest {
+		clients, path := registries.lookup(ctx, repository)
+		for _, client := range clients {
+			digest, manifests, err := client.Manifests(ctx, path, reference)
+			if err != nil {
+				continue
+			}
+
+			suggestions := []prompt.Suggest{}
+			if digest != "" && len(manifests) > 0 {
+				suggestions = append(suggestions, prompt.Suggest{Text: digest, Description: reference + ", every platform"})
+			} else if digest != "" {
+				suggestions = append(suggestions, prompt.Suggest{Text: digest, Description: reference})
+			}
+			for _, manifest := range manifests {
+				suggestions = append(suggestions, prompt.Suggest{Text: manifest.Digest, Description: reference + ", " + manifest.Platform})
+			}
+			return suggestions
+		}
+		return nil
+	})
+
+	return prompt.FilterHasPrefix(withPrefix(digests, name+"@"), word, true)
+}
+
+//imageKey : The registry host and repository of an image name, nginx and docker.io/library/nginx
+//share the same key
+func imageKey(name string) string {
+	host, repository := registry.SplitHost(name)
+	if host == "" {
+		return registry.HubRepository(repository)
+	}
+	return host + "/" + repository
+}
+
+func withPrefix(suggestions []prompt.Suggest, prefix string) []prompt.Suggest {
+	result := []prompt.Suggest{}
+	for _, s := range suggestions {
+		result = append(result, prompt.Suggest{Text: prefix + s.Text, Description: s.Description})
+	}
+	return result
+}
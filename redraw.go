@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/c-bata/go-prompt"
+)
+
+//redrawSequence : Fake input handed to go-prompt to have it ask the completer again, go-prompt
+//only completes after a key was read and has no other way to be redrawn
+var redrawSequence = []byte{0x1b, '[', 'r', 'd', '~'}
+
+//redraws : Pending redraw of the prompt, requests made while one is pending are merged into it
+var redraws = make(chan struct{}, 1)
+
+//requestRedraw : Redraws the prompt with fresh suggestions as soon as it reads input again
+func requestRedraw() {
+	select {
+	case redraws <- struct{}{}:
+	default:
+	}
+}
+
+//completionSelection : Mirrors the selection go-prompt's CompletionManager keeps to itself. It follows
+//the same keys with the same wrap rules over the suggestions the completer returned last:
+//Tab and Down move on, BackTab and Up go back, moving past either end selects nothing
+//and any other key ends the selection
+type completionSelection struct {
+	lock  sync.Mutex
+	index int
+	count int
+}
+
+var selection = &completionSelection{index: -1}
+
+func (s *completionSelection) key(key prompt.Key) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch key {
+	case prompt.Down:
+		if s.index != -1 {
+			s.next()
+		}
+	case prompt.Tab, prompt.ControlI:
+		s.next()
+	case prompt.Up:
+		if s.index != -1 {
+			s.previous()
+		}
+	case prompt.BackTab:
+		s.previous()
+	default:
+		s.index = -1
+	}
+}
+
+func (s *completionSelection) next() {
+	s.index++
+	if s.index >= s.count {
+		s.index = -1
+	}
+}
+
+func (s *completionSelection) previous() {
+	s.index--
+	if s.index < -1 {
+		s.index = s.count - 1
+	}
+}
+
+//suggested : Records how many suggestions go-prompt shows now
+func (s *completionSelection) suggested(count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.count = count
+}
+
+//reset : A new prompt starts without suggestions and nothing selected
+func (s *completionSelection) reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.index, s.count = -1, 0
+}
+
+//position : Index of the selected suggestion, false when none is selected
+func (s *completionSelection) position() (int, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.index, s.index != -1
+}
+
+//trackSelection : Wraps the completer so the selection knows how many suggestions go-prompt holds
+func trackSelection(completer prompt.Completer) prompt.Completer {
+	return func(d prompt.Document) []prompt.Suggest {
+		suggestions := completer(d)
+		selection.suggested(len(suggestions))
+		return suggestions
+	}
+}
+
+//redrawParser : Reads the terminal through go-prompt's own parser and feeds it redrawSequence
+//whenever a redraw was requested, the sequence maps to a key go-prompt ignores. Redraws wait
+//while a suggestion is selected since go-prompt would take it for the typed word
+type redrawParser struct {
+	prompt.ConsoleParser
+}
+
+func newRedrawParser() *redrawParser {
+	return &redrawParser{ConsoleParser: prompt.NewStandardInputParser()}
+}
+
+func (p *redrawParser) Read() ([]byte, error) {
+	if _, selecting := selection.position(); selecting {
+		return p.ConsoleParser.Read()
+	}
+
+	select {
+	case <-redraws:
+		return redrawSequence, nil
+	default:
+		return p.ConsoleParser.Read()
+	}
+}
+
+func (p *redrawParser) GetKey(b []byte) prompt.Key {
+	if bytes.Equal(b, redrawSequence) {
+		return prompt.Ignore
+	}
+
+	key := p.ConsoleParser.GetKey(b)
+	selection.key(key)
+	return key
+}
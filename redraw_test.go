@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/c-bata/go-prompt"
+)
+
+func TestCompletionSelectionFollowsGoPrompt(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		keys  []prompt.Key
+		want  int
+	}{
+		{name: "tab without suggestions", count: 0, keys: []prompt.Key{prompt.Tab}, want: -1},
+		{name: "down without selection", count: 3, keys: []prompt.Key{prompt.Down}, want: -1},
+		{name: "tab selects the first", count: 3, keys: []prompt.Key{prompt.Tab}, want: 0},
+		{name: "down moves on", count: 3, keys: []prompt.Key{prompt.Tab, prompt.Down}, want: 1},
+		{name: "past the end selects nothing", count: 2, keys: []prompt.Key{prompt.Tab, prompt.Tab, prompt.Tab}, want: -1},
+		{name: "wraps around", count: 2, keys: []prompt.Key{prompt.Tab, prompt.Tab, prompt.Tab, prompt.Tab}, want: 0},
+		{name: "backtab selects the last", count: 3, keys: []prompt.Key{prompt.BackTab}, want: 2},
+		{name: "up before the first selects nothing", count: 3, keys: []prompt.Key{prompt.Tab, prompt.Up}, want: -1},
+		{name: "typing ends the selection", count: 3, keys: []prompt.Key{prompt.Tab, prompt.NotDefined}, want: -1},
+	}
+
+	for _, tt := range tests {
+		s := &completionSelection{index: -1}
+		s.suggested(tt.count)
+		for _, key := range tt.keys {
+			s.key(key)
+		}
+		if index, _ := s.position(); index != tt.want {
+			t.Errorf("%s: selected %d, want %d", tt.name, index, tt.want)
+		}
+	}
+}
+
+//typedParser : A console parser that always reads the same key
+type typedParser struct {
+	prompt.ConsoleParser
+}
+
+func (typedParser) Read() ([]byte, error) {
+	return []byte("a"), nil
+}
+
+func TestRedrawWaitsForSelection(t *testing.T) {
+	defer selection.reset()
+	parser := &redrawParser{ConsoleParser: typedParser{}}
+	requestRedraw()
+
+	selection.reset()
+	selection.suggested(2)
+	selection.key(prompt.Tab)
+	if b, _ := parser.Read(); string(b) != "a" {
+		t.Errorf("read %q while a suggestion was selected, want the typed key", b)
+	}
+
+	selection.key(prompt.NotDefined)
+	if b, _ := parser.Read(); string(b) != string(redrawSequence) {
+		t.Errorf("read %q once the selection ended, want the pending redraw", b)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"docker.io/go-docker/api/types"
+	"github.com/c-bata/go-prompt"
+	"github.com/mstrYoda/docker-shell/lib/registry"
+)
+
+//registriesVariable : Comma separated registry hosts to offer for completion besides the ones
+//docker is logged into, e.g. registry.corp:5000,harbor.corp
+const registriesVariable = "DOCKER_SHELL_REGISTRIES"
+
+//registrySet : Clients of the registries images are pulled from, set up with the credentials
+//of the docker config and the insecure registries and mirrors of the daemon
+type registrySet struct {
+	lock          sync.Mutex
+	clients       map[string]*registry.Client
+	insecure      map[string]bool
+	insecureCIDRs []*net.IPNet
+	mirrors       []string
+	hosts         []string
+	configHosts   []string
+	configChanged time.Time
+}
+
+var registries = &registrySet{clients: map[string]*registry.Client{}, insecure: map[string]bool{}}
+
+//connect : Takes over the registry settings reported by docker info, clients created
+//before keep their settings
+func (r *registrySet) connect(info types.Info) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	config := info.RegistryConfig
+	if config == nil {
+		return
+	}
+
+	r.mirrors, r.insecureCIDRs, r.hosts = config.Mirrors, nil, nil
+	for _, cidr := range config.InsecureRegistryCIDRs {
+		if cidr != nil {
+			network := net.IPNet(*cidr)
+			r.insecureCIDRs = append(r.insecureCIDRs, &network)
+		}
+	}
+	for name, index := range config.IndexConfigs {
+		if index == nil || registry.IsHub(name) {
+			continue
+		}
+		r.insecure[name] = !index.Secure
+		r.hosts = append(r.hosts, name)
+	}
+}
+
+//connectRegistries : Asks the daemon which registries are insecure and which mirrors it uses
+func connectRegistries() {
+	ctx, cancel := resourceContext()
+	defer cancel()
+
+	if info, err := dockerClient.Info(ctx); err == nil {
+		registries.connect(info)
+	}
+}
+
+//hub : Clients for Docker Hub images, the mirrors of the daemon come before Docker Hub itself
+func (r *registrySet) hub(ctx context.Context) []*registry.Client {
+	r.lock.Lock()
+	mirrors := r.mirrors
+	r.lock.Unlock()
+
+	clients := []*registry.Client{}
+	for _, mirror := range mirrors {
+		clients = append(clients, r.client(ctx, strings.TrimSuffix(mirror, "/")))
+	}
+	return append(clients, r.client(ctx, registry.HubURL))
+}
+
+//lookup : Clients able to answer for the image name and its repository there
+func (r *registrySet) lookup(ctx context.Context, name string) ([]*registry.Client, string) {
+	host, repository := registry.SplitHost(name)
+	if host == "" {
+		return r.hub(ctx), registry.HubRepository(repository)
+	}
+	return []*registry.Client{r.client(ctx, host)}, repository
+}
+
+//client : The client of a registry host or endpoint, created on first use with the credentials
+//docker login stored for it and created again once the docker config changed
+func (r *registrySet) client(ctx context.Context, endpoint string) *registry.Client {
+	host := endpoint
+	if i := strings.Index(endpoint, "://"); i >= 0 {
+		host = endpoint[i+3:]
+	} else {
+		endpoint = "https://" + endpoint
+	}
+
+	r.sync()
+	r.lock.Lock()
+	client, ok := r.clients[endpoint]
+	r.lock.Unlock()
+	if ok {
+		return client
+	}
+
+	client = registry.New(endpoint)
+	if r.isInsecure(host) {
+		client = registry.NewInsecure(endpoint)
+	}
+	client.Credentials = dockerCredentials(ctx, host)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if existing, ok := r.clients[endpoint]; ok {
+		return existing
+	}
+	r.clients[endpoint] = client
+	return client
+}
+
+//isInsecure : Reports whether the daemon treats the registry as insecure, either by name
+//or because its address is in one of the insecure networks
+func (r *registrySet) isInsecure(host string) bool {
+	r.lock.Lock()
+	insecure, named := r.insecure[host]
+	networks := r.insecureCIDRs
+	r.lock.Unlock()
+	if named {
+		return insecure
+	}
+
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	addresses := []net.IP{net.ParseIP(hostname)}
+	if addresses[0] == nil {
+		addresses, _ = net.LookupIP(hostname)
+	}
+
+	for _, address := range addresses {
+		for _, network := range networks {
+			if network.Contains(address) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//hostSuggestions : Registry hosts starting with the word, configured ones first, then the
+//ones docker is logged into and the ones the daemon knows
+func (r *registrySet) hostSuggestions(word string) []prompt.Suggest {
+	hosts := append(r.loggedIn(), r.daemonHosts()...)
+
+	configured := []string{}
+	for _, host := range strings.Split(os.Getenv(registriesVariable), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			configured = append(configured, host)
+		}
+	}
+	sort.Strings(hosts)
+
+	suggestions := []prompt.Suggest{}
+	seen := map[string]bool{}
+	for _, host := range append(configured, hosts...) {
+		if seen[host] || !strings.HasPrefix(host, word) {
+			continue
+		}
+		seen[host] = true
+		suggestions = append(suggestions, prompt.Suggest{Text: host + "/", Description: "Registry"})
+	}
+	return suggestions
+}
+
+//daemonHosts : Registries the daemon has settings for
+func (r *registrySet) daemonHosts() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string{}, r.hosts...)
+}
+
+//loggedIn : Registries the docker config has credentials for, the config is only read
+//again once it changed
+func (r *registrySet) loggedIn() []string {
+	r.sync()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.configHosts == nil {
+		r.configHosts = []string{}
+		if config, err := registry.LoadConfig(dockerConfigPath()); err == nil {
+			r.configHosts = config.Hosts()
+		}
+	}
+	return append([]string{}, r.configHosts...)
+}
+
+//sync : Drops the clients and hosts taken from the docker config once it changed, e.g. because
+//docker login ran in the shell
+func (r *registrySet) sync() {
+	changed := dockerConfigChanged()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !changed.Equal(r.configChanged) {
+		r.configChanged, r.configHosts = changed, nil
+		r.clients = map[string]*registry.Client{}
+	}
+}
+
+//dockerConfigChanged : When the docker config was last written, zero when there is none
+func dockerConfigChanged() time.Time {
+	if info, err := os.Stat(dockerConfigPath()); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+func dockerConfigPath() string {
+	return filepath.Join(dockerConfigDir(), "config.json")
+}
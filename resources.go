@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"docker.io/go-docker/api/types"
+	"docker.io/go-docker/api/types/filters"
+	"github.com/c-bata/go-prompt"
+	commands "github.com/mstrYoda/docker-shell/lib"
+)
+
+//resourceArguments : Resource kinds accepted by a command per argument position
+type resourceArguments struct {
+	kinds []string
+	//multiple : The last kind repeats for every further argument
+	multiple bool
+}
+
+var resourceCommands = map[string]resourceArguments{
+	"config inspect":     {kinds: []string{"config"}, multiple: true},
+	"config rm":          {kinds: []string{"config"}, multiple: true},
+	"context export":     {kinds: []string{"context"}},
+	"context inspect":    {kinds: []string{"context"}, multiple: true},
+	"context rm":         {kinds: []string{"context"}, multiple: true},
+	"context update":     {kinds: []string{"context"}},
+	"context use":        {kinds: []string{"context"}},
+	"network connect":    {kinds: []string{"network", "container"}},
+	"network disconnect": {kinds: []string{"network", "container"}},
+	"network inspect":    {kinds: []string{"network"}, multiple: true},
+	"network rm":         {kinds: []string{"network"}, multiple: true},
+	"node demote":        {kinds: []string{"node"}, multiple: true},
+	"node inspect":       {kinds: []string{"node"}, multiple: true},
+	"node promote":       {kinds: []string{"node"}, multiple: true},
+	"node ps":            {kinds: []string{"node"}, multiple: true},
+	"node rm":            {kinds: []string{"node"}, multiple: true},
+	"node update":        {kinds: []string{"node"}},
+	"plugin disable":     {kinds: []string{"plugin"}},
+	"plugin enable":      {kinds: []string{"plugin"}},
+	"plugin inspect":     {kinds: []string{"plugin"}, multiple: true},
+	"plugin push":        {kinds: []string{"plugin"}},
+	"plugin rm":          {kinds: []string{"plugin"}, multiple: true},
+	"plugin set":         {kinds: []string{"plugin"}},
+	"plugin upgrade":     {kinds: []string{"plugin"}},
+	"secret inspect":     {kinds: []string{"secret"}, multiple: true},
+	"secret rm":          {kinds: []string{"secret"}, multiple: true},
+	"service inspect":    {kinds: []string{"service"}, multiple: true},
+	"service logs":       {kinds: []string{"service"}},
+	"service ps":         {kinds: []string{"service"}, multiple: true},
+	"service rm":         {kinds: []string{"service"}, multiple: true},
+	"service rollback":   {kinds: []string{"service"}},
+	"service scale":      {kinds: []string{"service-scale"}, multiple: true},
+	"service update":     {kinds: []string{"service"}},
+	"stack deploy":       {kinds: []string{"stack"}},
+	"stack ps":           {kinds: []string{"stack"}},
+	"stack rm":           {kinds: []string{"stack"}, multiple: true},
+	"stack services":     {kinds: []string{"stack"}},
+	"volume inspect":     {kinds: []string{"volume"}, multiple: true},
+	"volume rm":          {kinds: []string{"volume"}, multiple: true},
+}
+
+var resourceCompleters = map[string]func() []prompt.Suggest{
+	"config":    configCompleter,
+	"container": func() []prompt.Suggest { return containerListCompleter(nil) },
+	"context":   contextCompleter,
+	"network":   networkCompleter,
+	"node":      nodeCompleter,
+	"plugin":    pluginCompleter,
+	"secret":    secretCompleter,
+	"service":   serviceCompleter,
+	"service-scale": func() []prompt.Suggest {
+		return withSuffix(serviceCompleter(), "=")
+	},
+	"stack":  stackCompleter,
+	"volume": volumeCompleter,
+	"volume-mount": func() []prompt.Suggest {
+		return withSuffix(volumeCompleter(), ":")
+	},
+}
+
+//resourceArgumentCompleter : Suggests the resources a command accepts at the cursor position
+func resourceArgumentCompleter(ctx commands.Context, word string) ([]prompt.Suggest, bool) {
+	arguments, ok := resourceCommands[ctx.Command()]
+	if !ok {
+		return nil, false
+	}
+
+	index := ctx.ArgIndex()
+	if index >= len(arguments.kinds) {
+		if !arguments.multiple {
+			return nil, false
+		}
+		index = len(arguments.kinds) - 1
+	}
+
+	suggestions := resourceCompleters[arguments.kinds[index]]()
+	if arguments.multiple {
+		suggestions = excludeTyped(suggestions, ctx.Args)
+	}
+	return prompt.FilterFuzzy(suggestions, word, true), true
+}
+
+func configCompleter() []prompt.Suggest  { return memoryCache.Peek("configs", configList) }
+func secretCompleter() []prompt.Suggest  { return memoryCache.Peek("secrets", secretList) }
+func nodeCompleter() []prompt.Suggest    { return memoryCache.Peek("nodes", nodeList) }
+func serviceCompleter() []prompt.Suggest { return memoryCache.Peek("services", serviceList) }
+func stackCompleter() []prompt.Suggest   { return memoryCache.Peek("stacks", stackList) }
+func pluginCompleter() []prompt.Suggest  { return memoryCache.Peek("plugins", pluginList) }
+
+func withSuffix(suggestions []prompt.Suggest, suffix string) []prompt.Suggest {
+	result := []prompt.Suggest{}
+	for _, s := range suggestions {
+		result = append(result, prompt.Suggest{Text: s.Text + suffix, Description: s.Description})
+	}
+	return result
+}
+
+func excludeTyped(suggestions []prompt.Suggest, typed []string) []prompt.Suggest {
+	used := map[string]bool{}
+	for _, arg := range typed {
+		used[arg] = true
+	}
+
+	result := []prompt.Suggest{}
+	for _, s := range suggestions {
+		if !used[s.Text] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func resourceContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 2*time.Second)
+}
+
+func networkCompleter() []prompt.Suggest {
+	suggestions := []prompt.Suggest{}
+	for _, network := range model.networkList() {
+		suggestions = append(suggestions, prompt.Suggest{Text: network.Name, Description: network.Driver + " | " + network.Scope})
+	}
+	return suggestions
+}
+
+func volumeCompleter() []prompt.Suggest {
+	suggestions := []prompt.Suggest{}
+	for _, volume := range model.volumeList() {
+		suggestions = append(suggestions, prompt.Suggest{Text: volume.Name, Description: volume.Driver + " | " + volume.Mountpoint})
+	}
+	return suggestions
+}
+
+func configList(ctx context.Context) []prompt.Suggest {
+	configs, err := dockerClient.ConfigList(ctx, types.ConfigListOptions{})
+	if err != nil {
+		return nil
+	}
+	suggestions := []prompt.Suggest{}
+	for _, config := range configs {
+		suggestions = append(suggestions, prompt.Suggest{Text: config.Spec.Name, Description: config.ID})
+	}
+	return suggestions
+}
+
+func secretList(ctx context.Context) []prompt.Suggest {
+	secrets, err := dockerClient.SecretList(ctx, types.SecretListOptions{})
+	if err != nil {
+		return nil
+	}
+	suggestions := []prompt.Suggest{}
+	for _, secret := range secrets {
+		suggestions = append(suggestions, prompt.Suggest{Text: secret.Spec.Name, Description: secret.ID})
+	}
+	return suggestions
+}
+
+func nodeList(ctx context.Context) []prompt.Suggest {
+	nodes, err := dockerClient.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return nil
+	}
+	suggestions := []prompt.Suggest{}
+	for _, node := range nodes {
+		description := string(node.Spec.Role) + " | " + string(node.Status.State) + " | " + string(node.Spec.Availability)
+		suggestions = append(suggestions, prompt.Suggest{Text: node.Description.Hostname, Description: description})
+	}
+	return suggestions
+}
+
+func serviceList(ctx context.Context) []prompt.Suggest {
+	services, err := dockerClient.ServiceList(ctx, types.ServiceListOptions{})
+	if err != nil {
+		return nil
+	}
+	suggestions := []prompt.Suggest{}
+	for _, service := range services {
+		suggestions = append(suggestions, prompt.Suggest{Text: service.Spec.Name, Description: service.ID})
+	}
+	return suggestions
+}
+
+//stackList : Stacks only exist on the client side, they are the namespaces their services are labeled with
+func stackList(ctx context.Context) []prompt.Suggest {
+	services, err := dockerClient.ServiceList(ctx, types.ServiceListOptions{})
+	if err != nil {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, service := range services {
+		if namespace := service.Spec.Labels["com.docker.stack.namespace"]; namespace != "" {
+			counts[namespace]++
+		}
+	}
+
+	suggestions := []prompt.Suggest{}
+	for namespace, count := range counts {
+		suggestions = append(suggestions, prompt.Suggest{Text: namespace, Description: pluralize(count, "service")})
+	}
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Text < suggestions[j].Text })
+	return suggestions
+}
+
+func pluginList(ctx context.Context) []prompt.Suggest {
+	plugins, err := dockerClient.PluginList(ctx, filters.NewArgs())
+	if err != nil {
+		return nil
+	}
+	suggestions := []prompt.Suggest{}
+	for _, plugin := range plugins {
+		state := "disabled"
+		if plugin.Enabled {
+			state = "enabled"
+		}
+		suggestions = append(suggestions, prompt.Suggest{Text: plugin.Name, Description: state + " | " + plugin.Config.Description})
+	}
+	return suggestions
+}
+
+//contextCompleter : Contexts are stored by the docker CLI under its config directory
+func contextCompleter() []prompt.Suggest {
+	suggestions := []prompt.Suggest{{Text: "default", Description: "Current DOCKER_HOST based configuration"}}
+
+	metaDir := filepath.Join(dockerConfigDir(), "contexts", "meta")
+	dirs, _ := ioutil.ReadDir(metaDir)
+	for _, dir := range dirs {
+		content, err := ioutil.ReadFile(filepath.Join(metaDir, dir.Name(), "meta.json"))
+		if err != nil {
+			continue
+		}
+
+		meta := struct {
+			Name     string
+			Metadata struct{ Description string }
+		}{}
+		if json.Unmarshal(content, &meta) == nil && meta.Name != "" {
+			suggestions = append(suggestions, prompt.Suggest{Text: meta.Name, Description: meta.Metadata.Description})
+		}
+	}
+	return suggestions
+}
+
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".docker")
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
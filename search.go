@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+)
+
+const (
+	//searchPageSize : Results asked from DockerHub per page of a search
+	searchPageSize = 25
+	//searchDebounce : How long the word has to stay the same before DockerHub is searched for it
+	searchDebounce = 300 * time.Millisecond
+	//searchPrefetch : How close to the end of the loaded results the next page is asked for
+	searchPrefetch = 5
+)
+
+//hubSearch : The DockerHub search of the word being completed. A word is only searched once
+//typing paused, further pages are loaded while the suggestions are scrolled through
+type hubSearch struct {
+	lock    sync.Mutex
+	query   string
+	settled bool
+	pages   int
+	timer   *time.Timer
+	//lastPages : Last page of the searches known to have no further pages
+	lastPages map[string]int
+}
+
+var hubSearches = &hubSearch{lastPages: map[string]int{}}
+
+//suggestions : The results of the pages of the word loaded so far, searches for words typed
+//before the pause only show what is cached for them. Until results arrive, the images listed
+//for an empty word matching it are shown
+func (s *hubSearch) suggestions(word string) []prompt.Suggest {
+	s.lock.Lock()
+	if word != s.query {
+		s.query, s.settled, s.pages = word, word == "", 1
+		if s.timer != nil {
+			s.timer.Stop()
+		}
+		if !s.settled {
+			s.timer = time.AfterFunc(searchDebounce, func() { s.settle(word) })
+		}
+	}
+	settled, pages := s.settled, s.pages
+	last, known := s.lastPages[word]
+	s.lock.Unlock()
+
+	suggestions := []prompt.Suggest{}
+	for page := 1; page <= pages; page++ {
+		suggestions = append(suggestions, s.page(word, page, settled)...)
+	}
+
+	if len(suggestions) == 0 && word != "" {
+		all, _, _ := memoryCache.Get(imageCacheKey("", 1))
+		return prompt.FilterContains(all, word, true)
+	}
+
+	index, selecting := selection.position()
+	full := len(suggestions) >= pages*searchPageSize
+	if word == "" || !settled || !selecting || !full || (known && last <= pages) || index < len(suggestions)-searchPrefetch {
+		return suggestions
+	}
+
+	s.lock.Lock()
+	if s.query == word && s.pages == pages {
+		s.pages++
+	}
+	s.lock.Unlock()
+	return append(suggestions, s.page(word, pages+1, true)...)
+}
+
+//page : One page of the search, loaded in the background unless the word is not settled yet
+func (s *hubSearch) page(word string, page int, load bool) []prompt.Suggest {
+	key := imageCacheKey(word, page)
+	if load {
+		return memoryCache.Peek(key, imageLoader(word, page))
+	}
+
+	suggestions, _, _ := memoryCache.Get(key)
+	return suggestions
+}
+
+//settle : Searches the word once typing paused on it
+func (s *hubSearch) settle(word string) {
+	s.lock.Lock()
+	settled := s.query == word
+	if settled {
+		s.settled = true
+	}
+	s.lock.Unlock()
+
+	if settled {
+		requestRedraw()
+	}
+}
+
+//last : Records that the page is the last one of the search for the word
+func (s *hubSearch) last(word string, page int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.lastPages[word] = page
+}
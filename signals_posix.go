@@ -0,0 +1,64 @@
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+//forceKillWindow : A second Ctrl+C within this window kills the child process
+const forceKillWindow = 2 * time.Second
+
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT}
+
+//startInProcessGroup : Starts the child in its own process group, so a Ctrl+C reaches
+//the shell first and is delivered to the child only by forwardSignals
+func startInProcessGroup(ps *exec.Cmd) {
+	ps.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+//forwardSignals : Keeps the shell alive while the child runs by catching SIGINT, SIGTERM
+//and SIGQUIT and passing them on to the child or its process group. A child sharing the
+//terminal's process group already got SIGINT and SIGQUIT from the terminal, so it is only
+//sent SIGTERM and the SIGKILL of a second Ctrl+C. The returned function stops the
+//forwarding once the child exits
+func forwardSignals(ps *exec.Cmd, group bool) func() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, forwardedSignals...)
+	done := make(chan struct{})
+
+	go func() {
+		var lastInterrupt time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-sigCh:
+				if sig == syscall.SIGINT {
+					if time.Since(lastInterrupt) < forceKillWindow {
+						sig = syscall.SIGKILL
+					}
+					lastInterrupt = time.Now()
+				}
+				if !group && (sig == syscall.SIGINT || sig == syscall.SIGQUIT) {
+					continue
+				}
+
+				pid := ps.Process.Pid
+				if group {
+					pid = -pid
+				}
+				syscall.Kill(pid, sig.(syscall.Signal))
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
@@ -0,0 +1,43 @@
+// +build windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+)
+
+//forceKillWindow : A second Ctrl+C within this window kills the child process
+const forceKillWindow = 2 * time.Second
+
+func startInProcessGroup(ps *exec.Cmd) {}
+
+//forwardSignals : Keeps the shell alive while the child runs. The console already
+//delivers Ctrl+C to the child, so only a second Ctrl+C needs handling here
+func forwardSignals(ps *exec.Cmd, group bool) func() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	done := make(chan struct{})
+
+	go func() {
+		var lastInterrupt time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case <-sigCh:
+				if time.Since(lastInterrupt) < forceKillWindow {
+					ps.Process.Kill()
+				}
+				lastInterrupt = time.Now()
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	//refreshWorkers : Loads running at the same time, further refreshes wait for a free worker
+	refreshWorkers = 4
+	//persistedMaxAge : Persisted suggestions older than this are not loaded again, even offline
+	persistedMaxAge = 30 * 24 * time.Hour
+	//persistedSearches : Pages of image searches kept, the ones loaded last win
+	persistedSearches = 200
+	//searchPrefix : Key prefix of the pages of image searches, one entry per word and page
+	searchPrefix = "completer:"
+)
+
+//refreshTimeout : Deadline of a single load
+var refreshTimeout = 10 * time.Second
+
+//failedTTL : How long a key whose load failed is not loaded again, so a failing loader
+//does not run on every keystroke
+var failedTTL = 30 * time.Second
+
+//persistedTTLs : Suggestions coming from DockerHub and registries by key prefix, with how long
+//they stay fresh. They are kept in the cache file and never refreshed offline
+var persistedTTLs = map[string]time.Duration{
+	"all":        24 * time.Hour,
+	"catalog:":   time.Hour,
+	searchPrefix: time.Hour,
+	"digests:":   10 * time.Minute,
+	"tags:":      time.Hour,
+}
+
+//suggestionLoader : Computes the suggestions of a key, nil when the load failed
+type suggestionLoader func(ctx context.Context) []prompt.Suggest
+
+//storedSuggestions : Suggestions with the time they were loaded and the time the last load
+//failed, a failed load keeps what was loaded before
+type storedSuggestions struct {
+	suggestions []prompt.Suggest
+	loaded      time.Time
+	failed      time.Time
+}
+
+//persistedSuggestions : An entry of the cache file
+type persistedSuggestions struct {
+	Suggestions []prompt.Suggest `json:"suggestions"`
+	Loaded      time.Time        `json:"loaded"`
+}
+
+//suggestionStore : Suggestions shared by the completer and the background workers. The store owns
+//every entry: completers and workers only go through Get, Peek and Refresh, and concurrent
+//refreshes of the same key are collapsed into a single call of its loader.
+//Entries older than the ttl are still served while they are refreshed in the background.
+//Entries of persistedTTLs outlive the process in the cache file
+type suggestionStore struct {
+	ctx     context.Context
+	ttl     time.Duration
+	entries *cache.Cache
+	workers chan struct{}
+	//updated : Called once a refresh requested by Peek stored new suggestions
+	updated func()
+	//path : Cache file of the persisted entries, nothing is persisted when empty
+	path string
+	//offline : Persisted entries are only served from the cache file, never loaded
+	offline bool
+
+	lock     sync.Mutex
+	loading  map[string]bool
+	running  sync.WaitGroup
+	saveLock sync.Mutex
+}
+
+//newSuggestionStore : Entries are refreshed once older than ttl, the loaders are cancelled once ctx is
+//done and updated is called whenever suggestions a completer asked for arrived
+func newSuggestionStore(ctx context.Context, ttl time.Duration, updated func()) *suggestionStore {
+	return &suggestionStore{
+		ctx:     ctx,
+		ttl:     ttl,
+		entries: cache.New(cache.NoExpiration, 0),
+		workers: make(chan struct{}, refreshWorkers),
+		updated: updated,
+		loading: map[string]bool{},
+	}
+}
+
+//Get : The stored suggestions of the key and whether they are still fresh, without loading them
+func (s *suggestionStore) Get(key string) ([]prompt.Suggest, bool, bool) {
+	entry, found := s.entries.Get(key)
+	if !found {
+		return nil, false, false
+	}
+
+	stored := entry.(storedSuggestions)
+	ttl, _ := s.ttlOf(key)
+	fresh := time.Since(stored.loaded) < ttl || time.Since(stored.failed) < failedTTL
+	return stored.suggestions, true, fresh
+}
+
+//ttlOf : How long the suggestions of the key stay fresh and whether they are persisted
+func (s *suggestionStore) ttlOf(key string) (time.Duration, bool) {
+	for prefix, ttl := range persistedTTLs {
+		if strings.HasPrefix(key, prefix) {
+			return ttl, true
+		}
+	}
+	return s.ttl, false
+}
+
+//Set : Stores the suggestions of the key
+func (s *suggestionStore) Set(key string, suggestions []prompt.Suggest) {
+	s.entries.Set(key, storedSuggestions{suggestions: suggestions, loaded: time.Now()}, cache.DefaultExpiration)
+}
+
+//fail : Records a failed load of the key, the key is served as it is until failedTTL passed
+func (s *suggestionStore) fail(key string) {
+	stored := storedSuggestions{suggestions: []prompt.Suggest{}}
+	if entry, found := s.entries.Get(key); found {
+		stored = entry.(storedSuggestions)
+	}
+	stored.failed = time.Now()
+	s.entries.Set(key, stored, cache.DefaultExpiration)
+}
+
+//Peek : Whatever is stored for the key right away, stale or not. Missing or stale suggestions
+//are refreshed in the background and the prompt is redrawn once they arrived
+func (s *suggestionStore) Peek(key string, load suggestionLoader) []prompt.Suggest {
+	suggestions, found, fresh := s.Get(key)
+	if !fresh {
+		s.refresh(key, load, s.updated)
+	}
+	if !found {
+		return []prompt.Suggest{}
+	}
+	return suggestions
+}
+
+//Refresh : Loads the suggestions of the key in the background, unless a load is already running
+func (s *suggestionStore) Refresh(key string, load suggestionLoader) {
+	s.refresh(key, load, func() {})
+}
+
+//Wait : Blocks until every background load finished, loads stop early once the store's context is done
+func (s *suggestionStore) Wait() {
+	s.running.Wait()
+}
+
+func (s *suggestionStore) refresh(key string, load suggestionLoader, done func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, persisted := s.ttlOf(key); persisted && s.offline {
+		return
+	}
+	if s.loading[key] || s.ctx.Err() != nil {
+		return
+	}
+	s.loading[key] = true
+	s.running.Add(1)
+
+	go func() {
+		defer s.running.Done()
+		defer func() {
+			s.lock.Lock()
+			delete(s.loading, key)
+			s.lock.Unlock()
+		}()
+
+		select {
+		case s.workers <- struct{}{}:
+		case <-s.ctx.Done():
+			return
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, refreshTimeout)
+		suggestions := load(ctx)
+		cancel()
+		<-s.workers
+
+		if s.ctx.Err() != nil {
+			return
+		}
+		if suggestions == nil {
+			s.fail(key)
+			return
+		}
+
+		s.Set(key, suggestions)
+		if _, persisted := s.ttlOf(key); persisted {
+			s.save()
+		}
+		done()
+	}()
+}
+
+//Persist : Loads the persisted entries of the cache file at path and keeps it up to date from now on,
+//a missing file is an empty cache
+func (s *suggestionStore) Persist(path string) error {
+	s.path = path
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	persisted := map[string]persistedSuggestions{}
+	if err := json.Unmarshal(content, &persisted); err != nil {
+		return err
+	}
+	for key, entry := range persisted {
+		if _, ok := s.ttlOf(key); ok && entry.Suggestions != nil && time.Since(entry.Loaded) < persistedMaxAge {
+			s.entries.Set(key, storedSuggestions{suggestions: entry.Suggestions, loaded: entry.Loaded}, cache.DefaultExpiration)
+		}
+	}
+	return nil
+}
+
+//Offline : Stops loading persisted entries, only what the cache file had is served
+func (s *suggestionStore) Offline() {
+	s.offline = true
+}
+
+//save : Writes the persisted entries to the cache file, through a temporary file so a
+//crash never leaves a broken cache behind
+func (s *suggestionStore) save() {
+	if s.path == "" {
+		return
+	}
+
+	s.saveLock.Lock()
+	defer s.saveLock.Unlock()
+
+	content, err := json.Marshal(s.prune())
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return
+	}
+	temporary, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return
+	}
+	_, err = temporary.Write(content)
+	if closeErr := temporary.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temporary.Name())
+		return
+	}
+	if err := os.Rename(temporary.Name(), s.path); err != nil {
+		os.Remove(temporary.Name())
+	}
+}
+
+//prune : The entries to persist. Entries older than persistedMaxAge and all but the last
+//persistedSearches search pages are dropped from the store as well, so typing new words
+//does not grow the store and the cache file forever
+func (s *suggestionStore) prune() map[string]persistedSuggestions {
+	persisted := map[string]persistedSuggestions{}
+	searches := []string{}
+	for key, item := range s.entries.Items() {
+		stored := item.Object.(storedSuggestions)
+		if _, ok := s.ttlOf(key); !ok || stored.loaded.IsZero() {
+			continue
+		}
+		if time.Since(stored.loaded) >= persistedMaxAge {
+			s.entries.Delete(key)
+			continue
+		}
+
+		persisted[key] = persistedSuggestions{Suggestions: stored.suggestions, Loaded: stored.loaded}
+		if strings.HasPrefix(key, searchPrefix) {
+			searches = append(searches, key)
+		}
+	}
+
+	if len(searches) > persistedSearches {
+		sort.Slice(searches, func(i, j int) bool {
+			return persisted[searches[i]].Loaded.After(persisted[searches[j]].Loaded)
+		})
+		for _, key := range searches[persistedSearches:] {
+			delete(persisted, key)
+			s.entries.Delete(key)
+		}
+	}
+	return persisted
+}
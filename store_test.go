@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/patrickmn/go-cache"
+)
+
+//slowLoader : A loader blocking until released, counting its calls and how many run at once
+type slowLoader struct {
+	release chan struct{}
+	calls   int32
+	running int32
+	peak    int32
+}
+
+func newSlowLoader() *slowLoader {
+	return &slowLoader{release: make(chan struct{})}
+}
+
+func (l *slowLoader) load(text string) suggestionLoader {
+	return func(ctx context.Context) []prompt.Suggest {
+		atomic.AddInt32(&l.calls, 1)
+		running := atomic.AddInt32(&l.running, 1)
+		defer atomic.AddInt32(&l.running, -1)
+		for {
+			peak := atomic.LoadInt32(&l.peak)
+			if running <= peak || atomic.CompareAndSwapInt32(&l.peak, peak, running) {
+				break
+			}
+		}
+
+		select {
+		case <-l.release:
+			return []prompt.Suggest{{Text: text}}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func TestStoreLoadsOncePerKeyWhileInFlight(t *testing.T) {
+	store := newSuggestionStore(context.Background(), time.Minute, func() {})
+	loader := newSlowLoader()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			switch i % 4 {
+			case 0:
+				store.Peek("key", loader.load("loaded"))
+			case 1:
+				store.Refresh("key", loader.load("loaded"))
+			case 2:
+				store.Get("key")
+			case 3:
+				store.Set("key", []prompt.Suggest{{Text: "set"}})
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(loader.release)
+	store.Wait()
+
+	if calls := atomic.LoadInt32(&loader.calls); calls != 1 {
+		t.Errorf("loader ran %d times, want 1", calls)
+	}
+	if suggestions, found, fresh := store.Get("key"); !found || !fresh || suggestions[0].Text != "loaded" {
+		t.Errorf("Get = %v, %v, %v, want the loaded suggestions", suggestions, found, fresh)
+	}
+}
+
+func TestStoreLimitsConcurrentLoads(t *testing.T) {
+	store := newSuggestionStore(context.Background(), time.Minute, func() {})
+	loader := newSlowLoader()
+
+	keys := refreshWorkers * 3
+	for i := 0; i < keys; i++ {
+		store.Refresh(fmt.Sprintf("key-%d", i), loader.load("loaded"))
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&loader.running) < refreshWorkers && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(loader.release)
+	store.Wait()
+
+	if peak := atomic.LoadInt32(&loader.peak); peak != refreshWorkers {
+		t.Errorf("%d loads ran at once, want %d", peak, refreshWorkers)
+	}
+	if calls := atomic.LoadInt32(&loader.calls); int(calls) != keys {
+		t.Errorf("loader ran %d times, want %d", calls, keys)
+	}
+}
+
+func TestStoreCallsUpdatedAfterLoad(t *testing.T) {
+	updated := make(chan []prompt.Suggest, 1)
+	var store *suggestionStore
+	store = newSuggestionStore(context.Background(), time.Minute, func() {
+		suggestions, _, _ := store.Get("key")
+		updated <- suggestions
+	})
+	loader := newSlowLoader()
+
+	if suggestions := store.Peek("key", loader.load("loaded")); len(suggestions) != 0 {
+		t.Fatalf("Peek = %v before the load finished, want nothing", suggestions)
+	}
+	select {
+	case <-updated:
+		t.Fatal("updated was called before the load finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(loader.release)
+	select {
+	case suggestions := <-updated:
+		if len(suggestions) != 1 || suggestions[0].Text != "loaded" {
+			t.Errorf("updated saw %v, want the loaded suggestions", suggestions)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("updated was not called after the load")
+	}
+	store.Wait()
+}
+
+func TestStoreTimeoutFreesWorkers(t *testing.T) {
+	defer func(timeout time.Duration) { refreshTimeout = timeout }(refreshTimeout)
+	refreshTimeout = 20 * time.Millisecond
+
+	store := newSuggestionStore(context.Background(), time.Minute, func() {})
+	stuck := newSlowLoader()
+	for i := 0; i < refreshWorkers; i++ {
+		store.Refresh(fmt.Sprintf("stuck-%d", i), stuck.load("never"))
+	}
+	store.Wait()
+
+	if len(store.workers) != 0 {
+		t.Fatalf("%d workers still taken after the loads timed out", len(store.workers))
+	}
+	if suggestions, found, fresh := store.Get("stuck-0"); !found || !fresh || len(suggestions) != 0 {
+		t.Errorf("Get = %v, %v, %v after a timed out load, want a fresh empty entry", suggestions, found, fresh)
+	}
+
+	store.Refresh("key", func(ctx context.Context) []prompt.Suggest {
+		return []prompt.Suggest{{Text: "loaded"}}
+	})
+	store.Wait()
+	if _, found, _ := store.Get("key"); !found {
+		t.Error("no worker was free for a load after the timeouts")
+	}
+}
+
+func TestStoreDoesNotRetryFailedLoadsRightAway(t *testing.T) {
+	store := newSuggestionStore(context.Background(), time.Minute, func() {})
+	var calls int32
+	failing := func(ctx context.Context) []prompt.Suggest {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+
+	for i := 0; i < 5; i++ {
+		store.Peek("key", failing)
+		store.Wait()
+	}
+	if calls != 1 {
+		t.Errorf("failing loader ran %d times, want 1", calls)
+	}
+
+	store.Set("stale", []prompt.Suggest{{Text: "kept"}})
+	store.Refresh("stale", failing)
+	store.Wait()
+	if suggestions, _, _ := store.Get("stale"); len(suggestions) != 1 {
+		t.Errorf("a failed load replaced %v", suggestions)
+	}
+}
+
+func TestStorePrunesPersistedEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "suggestions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := newSuggestionStore(context.Background(), time.Minute, func() {})
+	store.path = filepath.Join(dir, "suggestions.json")
+	now := time.Now()
+	searches := persistedSearches + 10
+	for i := 0; i < searches; i++ {
+		loaded := now.Add(-time.Duration(i) * time.Minute)
+		store.entries.Set(imageCacheKey(fmt.Sprint("word", i), 1), storedSuggestions{suggestions: []prompt.Suggest{}, loaded: loaded}, cache.DefaultExpiration)
+	}
+	store.entries.Set("tags:old", storedSuggestions{suggestions: []prompt.Suggest{}, loaded: now.Add(-persistedMaxAge)}, cache.DefaultExpiration)
+	store.entries.Set("tags:new", storedSuggestions{suggestions: []prompt.Suggest{}, loaded: now}, cache.DefaultExpiration)
+	store.save()
+
+	reloaded := newSuggestionStore(context.Background(), time.Minute, func() {})
+	if err := reloaded.Persist(store.path); err != nil {
+		t.Fatal(err)
+	}
+	if count := reloaded.entries.ItemCount(); count != persistedSearches+1 {
+		t.Errorf("%d entries persisted, want %d: the newest searches and tags:new", count, persistedSearches+1)
+	}
+	for _, key := range []string{imageCacheKey("word0", 1), "tags:new"} {
+		if _, found, _ := reloaded.Get(key); !found {
+			t.Errorf("%s was not persisted", key)
+		}
+	}
+	for _, key := range []string{imageCacheKey(fmt.Sprint("word", searches-1), 1), "tags:old"} {
+		if _, found, _ := store.Get(key); found {
+			t.Errorf("%s was kept", key)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/term/termios"
+)
+
+type terminalState struct {
+	termios syscall.Termios
+}
+
+//saveTerminalState : Captures the terminal settings so they can be restored after
+//an interactive child process switched the terminal into raw mode
+func saveTerminalState() (*terminalState, error) {
+	state := &terminalState{}
+	if err := termios.Tcgetattr(os.Stdin.Fd(), &state.termios); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (s *terminalState) restore() error {
+	if err := termios.Tcsetattr(os.Stdin.Fd(), termios.TCSANOW, &s.termios); err != nil {
+		return err
+	}
+
+	return syscall.SetNonblock(int(os.Stdin.Fd()), false)
+}
@@ -0,0 +1,13 @@
+// +build windows
+
+package main
+
+type terminalState struct{}
+
+func saveTerminalState() (*terminalState, error) {
+	return &terminalState{}, nil
+}
+
+func (s *terminalState) restore() error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	commands "github.com/mstrYoda/docker-shell/lib"
+)
+
+//flagValueCompleter : Suggests values for the flag under the cursor based on its value type in the catalog,
+//values conflicting with the flags typed so far are marked
+func flagValueCompleter(ctx commands.Context, word string) []prompt.Suggest {
+	value, ok := catalog().GetFlagValue(ctx.Command(), ctx.Flag)
+	if !ok {
+		return []prompt.Suggest{}
+	}
+
+	if len(value.Values) > 0 {
+		return catalog().MarkConflictingValues(ctx, prompt.FilterHasPrefix(value.Values, word, true))
+	}
+
+	return catalog().MarkConflictingValues(ctx, valueTypeCompleter(value.Type, word))
+}
+
+//valueTypeCompleter : Suggests values of the given type, for flag values and positional arguments alike
+func valueTypeCompleter(valueType commands.ValueType, word string) []prompt.Suggest {
+	switch valueType {
+	case commands.PathValue:
+		return localPathCompleter(word)
+	case commands.PortValue:
+		return prompt.FilterHasPrefix(portValueCompleter(), word, true)
+	case commands.ImageValue:
+		return imageCompleter(word)
+	case commands.EnvValue:
+		return prompt.FilterHasPrefix(environmentCompleter(), word, true)
+	case commands.LogDriverValue:
+		return prompt.FilterHasPrefix(logDriverCompleter(), word, true)
+	}
+
+	if completer, ok := resourceCompleters[string(valueType)]; ok {
+		return prompt.FilterFuzzy(completer(), word, true)
+	}
+	return []prompt.Suggest{}
+}
+
+//portValueCompleter : Port mappings of the exposed ports of local images, without the -p prefix
+func portValueCompleter() []prompt.Suggest {
+	suggestions := []prompt.Suggest{}
+	for _, s := range portMappingSuggestion() {
+		suggestions = append(suggestions, prompt.Suggest{Text: strings.TrimPrefix(s.Text, "-p "), Description: s.Description})
+	}
+	return suggestions
+}
+
+//environmentCompleter : Variables of the local environment, -e NAME passes them on to the container
+func environmentCompleter() []prompt.Suggest {
+	suggestions := []prompt.Suggest{}
+	for _, variable := range os.Environ() {
+		name := strings.SplitN(variable, "=", 2)[0]
+		suggestions = append(suggestions, prompt.Suggest{Text: name, Description: "Pass " + name + " from the local environment"})
+	}
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Text < suggestions[j].Text })
+	return suggestions
+}
+
+//logDriverCompleter : Logging drivers reported by docker info
+func logDriverCompleter() []prompt.Suggest {
+	return memoryCache.Peek("log-drivers", logDrivers)
+}
+
+func logDrivers(ctx context.Context) []prompt.Suggest {
+	info, err := dockerClient.Info(ctx)
+	if err != nil {
+		return nil
+	}
+
+	suggestions := []prompt.Suggest{}
+	for _, driver := range info.Plugins.Log {
+		description := "Logging driver"
+		if driver == info.LoggingDriver {
+			description = "Default logging driver"
+		}
+		suggestions = append(suggestions, prompt.Suggest{Text: driver, Description: description})
+	}
+	return suggestions
+}